@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+const (
+	avatarUploadMaxBytes = 5 << 20
+	// a small file can still decode to a huge image, checked from its header
+	avatarUploadMaxPixels   = 4096 * 4096
+	avatarUploadJPEGQuality = 85
+	// the size users.avatar_url points to once approved
+	avatarUploadDisplaySize = 256
+
+	// the level_curve unlock that skips the moderation queue
+	avatarAutoApproveUnlock = "avatar_auto_approve"
+
+	avatarUploadsDefaultPerPage = 50
+	avatarUploadsMaxPerPage     = 200
+)
+
+// avatarUploadSizes are the square sizes an upload is stored at.
+var avatarUploadSizes = []int{64, 128, 256, 512}
+
+// avatarUploadMimeTypes are the accepted uploads, by their content (not
+// the client's content type).
+var avatarUploadMimeTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// errAvatarTooManyPixels is returned by processAvatarUpload for an image
+// larger than avatarUploadMaxPixels.
+var errAvatarTooManyPixels = fmt.Errorf("avatar larger than %d pixels", avatarUploadMaxPixels)
+
+// avatarReviewSubmission is the reason an admin rejects an upload for.
+type avatarReviewSubmission struct {
+	Reason string `json:"reason"`
+}
+
+func registerAvatarUploadRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Upload Avatar (multipart "avatar" file)
+	// Stored square cropped at avatarUploadSizes (as jpeg, so without the
+	// EXIF data) and pending until an admin approves it, unless the user's
+	// level unlocks avatarAutoApproveUnlock.
+	e.Router.POST("/api/me/avatar", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		files, err := re.FindUploadedFiles("avatar")
+		if err != nil || len(files) == 0 {
+			return apiError(re, http.StatusBadRequest, "invalid_avatar", err)
+		}
+		if files[0].Size > avatarUploadMaxBytes {
+			return apiError(re, http.StatusBadRequest, "avatar_too_large", nil)
+		}
+
+		images, err := processAvatarUpload(files[0])
+		if errors.Is(err, errAvatarTooManyPixels) {
+			return apiError(re, http.StatusBadRequest, "avatar_too_many_pixels", err)
+		}
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "invalid_avatar", err)
+		}
+
+		curve, err := loadLevelCurve(app)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+		trusted := slices.Contains(levelUnlocks(curve, authRecord.GetInt("level")), avatarAutoApproveUnlock)
+
+		var upload *core.Record
+		err = app.RunInTransaction(func(txApp core.App) error {
+			// only the latest upload waits for a review, the ones it supersedes
+			// are deleted with their files
+			pending, err := txApp.FindAllRecords(
+				"avatar_uploads",
+				dbx.HashExp{"user": authRecord.Id, "status": "pending"},
+			)
+			if err != nil {
+				return err
+			}
+			for _, p := range pending {
+				if err := txApp.Delete(p); err != nil {
+					return err
+				}
+			}
+
+			collection, err := txApp.FindCachedCollectionByNameOrId("avatar_uploads")
+			if err != nil {
+				return err
+			}
+
+			upload = core.NewRecord(collection)
+			upload.Set("user", authRecord.Id)
+			upload.Set("images", images)
+			upload.Set("status", "pending")
+			if err := txApp.Save(upload); err != nil {
+				return err
+			}
+
+			if trusted {
+				return approveAvatarUpload(txApp, upload, false)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+			"upload":  avatarUploadItem(upload),
+		})
+	})
+
+	// ROUTE: Avatar Moderation Queue (admins, ?status=pending|approved|rejected|reverted&page=&perPage=)
+	e.Router.GET("/api/admin/avatar-uploads", func(re *core.RequestEvent) error {
+		query := re.Request.URL.Query()
+
+		status := query.Get("status")
+		if status == "" {
+			status = "pending"
+		}
+
+		page, _ := strconv.Atoi(query.Get("page"))
+		page = max(page, 1)
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+		if perPage <= 0 {
+			perPage = avatarUploadsDefaultPerPage
+		}
+		perPage = min(perPage, avatarUploadsMaxPerPage)
+
+		uploads, err := app.FindRecordsByFilter(
+			"avatar_uploads",
+			"status = {:status}",
+			"created",
+			perPage, (page-1)*perPage,
+			dbx.Params{"status": status},
+		)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		if errs := app.ExpandRecords(uploads, []string{"user"}, nil); len(errs) > 0 {
+			log.Println("Avatar uploads expand errors:", errs)
+		}
+
+		items := make([]map[string]any, 0, len(uploads))
+		for _, upload := range uploads {
+			item := avatarUploadItem(upload)
+			if user := upload.ExpandedOne("user"); user != nil {
+				item["user"] = map[string]any{
+					"id":         user.Id,
+					"username":   user.GetString("username"),
+					"avatar_url": user.GetString("avatar_url"),
+				}
+			}
+			items = append(items, item)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items":   items,
+			"page":    page,
+			"perPage": perPage,
+		})
+	}).Bind(apis.RequireSuperuserAuth())
+
+	// ROUTE: Approve Avatar (admins, a pending upload becomes the user's avatar)
+	e.Router.POST("/api/admin/avatar-uploads/{id}/approve", func(re *core.RequestEvent) error {
+		upload, err := app.FindRecordById("avatar_uploads", re.Request.PathValue("id"))
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "avatar_upload_not_found", err)
+		}
+		if upload.GetString("status") != "pending" {
+			return apiError(re, http.StatusBadRequest, "invalid_avatar_upload_status", nil)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			return approveAvatarUpload(txApp, upload, true)
+		})
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+			"upload":  avatarUploadItem(upload),
+		})
+	}).Bind(apis.RequireSuperuserAuth())
+
+	// ROUTE: Reject Avatar (admins, {"reason": ""}, a pending upload is never shown)
+	e.Router.POST("/api/admin/avatar-uploads/{id}/reject", func(re *core.RequestEvent) error {
+		var body avatarReviewSubmission
+		if err := re.BindBody(&body); err != nil {
+			return apiError(re, http.StatusBadRequest, "invalid_request", err)
+		}
+
+		upload, err := app.FindRecordById("avatar_uploads", re.Request.PathValue("id"))
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "avatar_upload_not_found", err)
+		}
+		if upload.GetString("status") != "pending" {
+			return apiError(re, http.StatusBadRequest, "invalid_avatar_upload_status", nil)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			return closeAvatarUpload(txApp, upload, "rejected", strings.TrimSpace(body.Reason))
+		})
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+			"upload":  avatarUploadItem(upload),
+		})
+	}).Bind(apis.RequireSuperuserAuth())
+
+	// ROUTE: Revert Avatar (admins, {"reason": ""}, an approved upload is taken
+	// down and the user's previous avatar restored)
+	e.Router.POST("/api/admin/avatar-uploads/{id}/revert", func(re *core.RequestEvent) error {
+		var body avatarReviewSubmission
+		if err := re.BindBody(&body); err != nil {
+			return apiError(re, http.StatusBadRequest, "invalid_request", err)
+		}
+
+		upload, err := app.FindRecordById("avatar_uploads", re.Request.PathValue("id"))
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "avatar_upload_not_found", err)
+		}
+		if upload.GetString("status") != "approved" {
+			return apiError(re, http.StatusBadRequest, "invalid_avatar_upload_status", nil)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			return closeAvatarUpload(txApp, upload, "reverted", strings.TrimSpace(body.Reason))
+		})
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+			"upload":  avatarUploadItem(upload),
+		})
+	}).Bind(apis.RequireSuperuserAuth())
+}
+
+// registerAvatarUploadHooks keeps the users' avatars going through the
+// moderation queue: only the admins set users.avatar_url and avatar directly.
+func registerAvatarUploadHooks(app core.App) {
+	app.OnRecordCreateRequest("users").BindFunc(func(e *core.RecordRequestEvent) error {
+		if !e.HasSuperuserAuth() {
+			// the generated avatar is set on create
+			e.Record.Set("avatar_url", "")
+			e.Record.Set("avatar", nil)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordUpdateRequest("users").BindFunc(func(e *core.RecordRequestEvent) error {
+		if !e.HasSuperuserAuth() {
+			e.Record.Set("avatar_url", e.Record.Original().GetString("avatar_url"))
+			e.Record.Set("avatar", e.Record.Original().Get("avatar"))
+		}
+		return e.Next()
+	})
+}
+
+// processAvatarUpload checks the uploaded image and returns it center
+// cropped to a square at each of avatarUploadSizes, re-encoded as jpeg
+// (dropping the EXIF and other metadata, once the orientation is applied).
+func processAvatarUpload(file *filesystem.File) ([]*filesystem.File, error) {
+	reader, err := file.Reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, avatarUploadMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > avatarUploadMaxBytes {
+		return nil, fmt.Errorf("avatar larger than %d bytes", avatarUploadMaxBytes)
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !slices.Contains(avatarUploadMimeTypes, mimeType) {
+		return nil, fmt.Errorf("unsupported avatar type %q", mimeType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width > avatarUploadMaxPixels || config.Height > avatarUploadMaxPixels ||
+		config.Width*config.Height > avatarUploadMaxPixels {
+		return nil, errAvatarTooManyPixels
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]*filesystem.File, 0, len(avatarUploadSizes))
+	for _, size := range avatarUploadSizes {
+		square := imaging.Fill(img, size, size, imaging.Center, imaging.Lanczos)
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, square, imaging.JPEG, imaging.JPEGQuality(avatarUploadJPEGQuality)); err != nil {
+			return nil, err
+		}
+
+		variant, err := filesystem.NewFileFromBytes(buf.Bytes(), fmt.Sprintf("avatar_%d.jpg", size))
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, variant)
+	}
+
+	return images, nil
+}
+
+// approveAvatarUpload makes the upload the user's avatar, remembering the
+// one it replaces for a revert.
+func approveAvatarUpload(app core.App, upload *core.Record, notify bool) error {
+	user, err := app.FindRecordById("users", upload.GetString("user"))
+	if err != nil {
+		return err
+	}
+
+	upload.Set("status", "approved")
+	upload.Set("previous_avatar_url", user.GetString("avatar_url"))
+	upload.Set("reviewed_at", time.Now().UTC())
+	if err := app.Save(upload); err != nil {
+		return err
+	}
+
+	user.Set("avatar_url", avatarUploadURL(upload, avatarUploadDisplaySize))
+	if err := app.Save(user); err != nil {
+		return err
+	}
+
+	if !notify {
+		return nil
+	}
+
+	return notifyAvatarReview(app, upload)
+}
+
+// closeAvatarUpload rejects or reverts the upload. A reverted avatar is
+// replaced by the previous one, unless the user got a newer one since.
+func closeAvatarUpload(app core.App, upload *core.Record, status string, reason string) error {
+	if status == "reverted" {
+		user, err := app.FindRecordById("users", upload.GetString("user"))
+		if err != nil {
+			return err
+		}
+
+		if user.GetString("avatar_url") == avatarUploadURL(upload, avatarUploadDisplaySize) {
+			previous := upload.GetString("previous_avatar_url")
+			if previous == "" {
+				previous = defaultAvatarURL(user.GetString("username"))
+			}
+			user.Set("avatar_url", previous)
+			if err := app.Save(user); err != nil {
+				return err
+			}
+		}
+	}
+
+	upload.Set("status", status)
+	upload.Set("reject_reason", reason)
+	upload.Set("reviewed_at", time.Now().UTC())
+	if err := app.Save(upload); err != nil {
+		return err
+	}
+
+	return notifyAvatarReview(app, upload)
+}
+
+// notifyAvatarReview tells the user about the admin's decision
+// (avatar_approved, avatar_rejected or avatar_reverted).
+func notifyAvatarReview(app core.App, upload *core.Record) error {
+	collection, err := app.FindCachedCollectionByNameOrId("notifications")
+	if err != nil {
+		return err
+	}
+
+	notification := core.NewRecord(collection)
+	notification.Set("user", upload.GetString("user"))
+	notification.Set("type", "avatar_"+upload.GetString("status"))
+	notification.Set("data", map[string]any{
+		"upload": upload.Id,
+		"reason": upload.GetString("reject_reason"),
+	})
+
+	return app.Save(notification)
+}
+
+// avatarUploadURL is the url of the upload at one of avatarUploadSizes
+// ("" if it has none of that size), relative to the API url like the
+// generated avatars.
+func avatarUploadURL(upload *core.Record, size int) string {
+	prefix := fmt.Sprintf("avatar_%d_", size)
+	for _, name := range upload.GetStringSlice("images") {
+		if strings.HasPrefix(name, prefix) {
+			return "/api/files/" + upload.BaseFilesPath() + "/" + name
+		}
+	}
+	return ""
+}
+
+func avatarUploadItem(upload *core.Record) map[string]any {
+	images := map[string]string{}
+	for _, size := range avatarUploadSizes {
+		images[fmt.Sprint(size)] = avatarUploadURL(upload, size)
+	}
+
+	return map[string]any{
+		"id":            upload.Id,
+		"status":        upload.GetString("status"),
+		"images":        images,
+		"reject_reason": upload.GetString("reject_reason"),
+		"reviewed_at":   upload.GetDateTime("reviewed_at").String(),
+		"created":       upload.GetDateTime("created").String(),
+	}
+}
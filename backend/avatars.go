@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	avatarDefaultSize = 128
+	avatarMinSize     = 16
+	avatarMaxSize     = 512
+
+	// the identicon is an avatarGridSize² grid, mirrored left to right,
+	// with half a cell of margin
+	avatarGridSize = 5
+
+	// the same seed always renders the same avatar
+	avatarCacheControl = "public, max-age=31536000, immutable"
+)
+
+var avatarBackground = color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+
+func registerAvatarRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Generated Avatar (/api/avatars/{seed}[.png|.svg]?size=)
+	// A deterministic identicon of the seed (the username at signup),
+	// PNG unless the seed ends with .svg.
+	e.Router.GET("/api/avatars/{seed}", func(re *core.RequestEvent) error {
+		seed := re.Request.PathValue("seed")
+		format := "png"
+		switch {
+		case strings.HasSuffix(seed, ".svg"):
+			seed, format = strings.TrimSuffix(seed, ".svg"), "svg"
+		case strings.HasSuffix(seed, ".png"):
+			seed = strings.TrimSuffix(seed, ".png")
+		}
+
+		size, _ := strconv.Atoi(re.Request.URL.Query().Get("size"))
+		if size <= 0 {
+			size = avatarDefaultSize
+		}
+		size = min(max(size, avatarMinSize), avatarMaxSize)
+
+		etag := fmt.Sprintf(`"%s-%s-%d"`, avatarHash(seed)[:16], format, size)
+		re.Response.Header().Set("Cache-Control", avatarCacheControl)
+		re.Response.Header().Set("ETag", etag)
+		if re.Request.Header.Get("If-None-Match") == etag {
+			return re.NoContent(http.StatusNotModified)
+		}
+
+		cells, fg := avatarIdenticon(seed)
+
+		if format == "svg" {
+			return re.Blob(http.StatusOK, "image/svg+xml", avatarSVG(cells, fg))
+		}
+
+		body, err := avatarPNG(cells, fg, size)
+		if err != nil {
+			return err
+		}
+
+		return re.Blob(http.StatusOK, "image/png", body)
+	})
+}
+
+// defaultAvatarURL is the generated avatar of seed, relative to the API url
+// so it doesn't depend on the host the app is served from.
+func defaultAvatarURL(seed string) string {
+	return "/api/avatars/" + url.PathEscape(seed) + ".png"
+}
+
+func avatarHash(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// avatarIdenticon returns the filled cells of the seed's identicon grid and
+// its color: the first bytes of the seed hash fill the left columns (mirrored
+// to the right ones), the last ones pick the hue.
+func avatarIdenticon(seed string) ([avatarGridSize][avatarGridSize]bool, color.RGBA) {
+	sum := sha256.Sum256([]byte(seed))
+
+	var cells [avatarGridSize][avatarGridSize]bool
+	half := (avatarGridSize + 1) / 2
+	for row := range avatarGridSize {
+		for col := range half {
+			filled := sum[row*half+col]%2 == 0
+			cells[row][col] = filled
+			cells[row][avatarGridSize-1-col] = filled
+		}
+	}
+
+	hue := float64(int(sum[29])<<8|int(sum[30])) / 65536 * 360
+	saturation := 0.45 + float64(sum[31]%20)/100
+
+	return cells, hslToRGB(hue, saturation, 0.55)
+}
+
+// avatarPNG renders the identicon at size×size pixels.
+func avatarPNG(cells [avatarGridSize][avatarGridSize]bool, fg color.RGBA, size int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	// the cell a pixel is in, -1 in the margin
+	cellAt := func(p int) int {
+		c := math.Floor(float64(p)*float64(avatarGridSize+1)/float64(size) - 0.5)
+		if c < 0 || c >= avatarGridSize {
+			return -1
+		}
+		return int(c)
+	}
+
+	for y := range size {
+		row := cellAt(y)
+		for x := range size {
+			col := cellAt(x)
+			if row >= 0 && col >= 0 && cells[row][col] {
+				img.SetRGBA(x, y, fg)
+			} else {
+				img.SetRGBA(x, y, avatarBackground)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// avatarSVG renders the identicon with 10 units per cell.
+func avatarSVG(cells [avatarGridSize][avatarGridSize]bool, fg color.RGBA) []byte {
+	const cell = 10
+	side := (avatarGridSize + 1) * cell
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`, side, side, hexColor(avatarBackground))
+	for row := range avatarGridSize {
+		for col := range avatarGridSize {
+			if cells[row][col] {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+					cell/2+col*cell, cell/2+row*cell, cell, cell, hexColor(fg))
+			}
+		}
+	}
+	b.WriteString(`</svg>`)
+
+	return []byte(b.String())
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// hslToRGB converts a hue (0-360), saturation and lightness (0-1) color.
+func hslToRGB(h float64, s float64, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g, b = c, x, 0
+	case hp < 2:
+		r, g, b = x, c, 0
+	case hp < 3:
+		r, g, b = 0, c, x
+	case hp < 4:
+		r, g, b = 0, x, c
+	case hp < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	m := l - c/2
+	return color.RGBA{
+		R: uint8((r + m) * 255),
+		G: uint8((g + m) * 255),
+		B: uint8((b + m) * 255),
+		A: 0xff,
+	}
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	bannerEventsMaxBatch = 50
+	// the beacons buffered between two flushes at most, the next ones are
+	// dropped until the flush
+	bannerEventsMaxPending = 20000
+	// how long the already counted (banner, viewer, day) are remembered
+	bannerEventsRetentionDays = 3
+	// default range of the CTR report
+	bannerReportDefaultDays = 7
+)
+
+var bannerEventTypes = []string{"impression", "click"}
+
+// bannerEvent is one counted beacon: a viewer (user id or "ip:...") saw or
+// clicked a banner on a (UTC) day.
+type bannerEvent struct {
+	Banner string
+	Viewer string
+	Day    string
+	Type   string
+}
+
+// bannerEventBuffer collects the beacons between two flushes, so the hot
+// home screen doesn't write to the database on every banner swipe.
+// The duplicates within a batch are dropped right away, and so are the
+// beacons of the banners that don't exist (the ids are kept in memory, in
+// sync with the banners collection).
+type bannerEventBuffer struct {
+	mu      sync.Mutex
+	pending map[bannerEvent]time.Time // -> hour of the first beacon
+	banners map[string]bool
+}
+
+var bannerEvents = &bannerEventBuffer{
+	pending: map[bannerEvent]time.Time{},
+	banners: map[string]bool{},
+}
+
+// setBanner marks whether the banner exists.
+func (b *bannerEventBuffer) setBanner(id string, exists bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if exists {
+		b.banners[id] = true
+	} else {
+		delete(b.banners, id)
+	}
+}
+
+// loadBanners replaces the known banner ids with the ones in the database.
+func (b *bannerEventBuffer) loadBanners(app core.App) error {
+	var ids []string
+	if err := app.DB().Select("id").From("banners").Column(&ids); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.banners = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		b.banners[id] = true
+	}
+	return nil
+}
+
+// add buffers a beacon, it returns false if the banner doesn't exist.
+func (b *bannerEventBuffer) add(banner string, viewer string, eventType string, now time.Time) bool {
+	event := bannerEvent{
+		Banner: banner,
+		Viewer: viewer,
+		Day:    now.Format("2006-01-02"),
+		Type:   eventType,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.banners[banner] {
+		return false
+	}
+	if _, ok := b.pending[event]; !ok && len(b.pending) < bannerEventsMaxPending {
+		b.pending[event] = now.Truncate(time.Hour)
+	}
+	return true
+}
+
+func (b *bannerEventBuffer) drain() map[bannerEvent]time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := b.pending
+	b.pending = map[bannerEvent]time.Time{}
+	return events
+}
+
+func (b *bannerEventBuffer) requeue(events map[bannerEvent]time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for event, hour := range events {
+		if len(b.pending) >= bannerEventsMaxPending {
+			return
+		}
+		if _, ok := b.pending[event]; !ok {
+			b.pending[event] = hour
+		}
+	}
+}
+
+type bannerEventSubmission struct {
+	BannerId string `json:"banner_id"`
+	Type     string `json:"type"`
+}
+
+func registerBannerRoutes(app core.App, e *core.ServeEvent) {
+	if err := bannerEvents.loadBanners(app); err != nil {
+		log.Println("Banner events error:", err)
+	}
+
+	// ROUTE: Banner Impression / Click Beacons
+	for _, eventType := range bannerEventTypes {
+		e.Router.POST("/api/banners/{id}/"+eventType, func(re *core.RequestEvent) error {
+			if !bannerEvents.add(re.Request.PathValue("id"), bannerViewer(re), eventType, time.Now().UTC()) {
+				return apiError(re, http.StatusNotFound, "banner_not_found", nil)
+			}
+			return re.NoContent(http.StatusNoContent)
+		})
+	}
+
+	// ROUTE: Batched Banner Beacons ({"events": [{"banner_id": "...", "type": "impression|click"}]})
+	e.Router.POST("/api/banners/events", func(re *core.RequestEvent) error {
+		var body struct {
+			Events []bannerEventSubmission `json:"events"`
+		}
+		if err := re.BindBody(&body); err != nil {
+			return apiError(re, http.StatusBadRequest, "invalid_request", err)
+		}
+		if len(body.Events) > bannerEventsMaxBatch {
+			return apiError(re, http.StatusBadRequest, "too_many_banner_events", nil)
+		}
+
+		viewer := bannerViewer(re)
+		now := time.Now().UTC()
+		for _, event := range body.Events {
+			if event.BannerId == "" || !slices.Contains(bannerEventTypes, event.Type) {
+				return apiError(re, http.StatusBadRequest, "invalid_request", nil)
+			}
+			// the unknown banners (e.g. deleted since the client loaded
+			// them) are skipped
+			bannerEvents.add(event.BannerId, viewer, event.Type, now)
+		}
+
+		return re.NoContent(http.StatusNoContent)
+	})
+
+	// ROUTE: Banner CTR Report (?from=YYYY-MM-DD&to=YYYY-MM-DD&banner=), superusers only
+	e.Router.GET("/api/admin/banners/ctr", func(re *core.RequestEvent) error {
+		query := re.Request.URL.Query()
+
+		now := time.Now().UTC()
+		to := now.Truncate(24*time.Hour).AddDate(0, 0, 1)
+		if v := query.Get("to"); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "invalid_date", err)
+			}
+			to = t.AddDate(0, 0, 1) // inclusive
+		}
+		from := to.AddDate(0, 0, -bannerReportDefaultDays)
+		if v := query.Get("from"); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "invalid_date", err)
+			}
+			from = t
+		}
+
+		// include the beacons that are still buffered
+		if err := flushBannerEvents(app); err != nil {
+			log.Println("Banner events error:", err)
+		}
+
+		params := dbx.Params{
+			"from": from.Format(types.DefaultDateLayout),
+			"to":   to.Format(types.DefaultDateLayout),
+		}
+
+		type BannerCTR struct {
+			ID          string  `db:"id" json:"id"`
+			Title       string  `db:"title" json:"title"`
+			IsActive    bool    `db:"is_active" json:"is_active"`
+			Impressions int     `db:"impressions" json:"impressions"`
+			Clicks      int     `db:"clicks" json:"clicks"`
+			CTR         float64 `db:"-" json:"ctr"`
+		}
+		var banners []BannerCTR
+		err := app.DB().NewQuery(`
+			SELECT b.id, b.title, b.is_active,
+				COALESCE(SUM(s.impressions), 0) AS impressions,
+				COALESCE(SUM(s.clicks), 0) AS clicks
+			FROM banners b
+			LEFT JOIN banner_stats s ON s.banner = b.id AND s.hour >= {:from} AND s.hour < {:to}
+			GROUP BY b.id
+			ORDER BY impressions DESC, b.title ASC
+		`).Bind(params).All(&banners)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		for i := range banners {
+			banners[i].CTR = bannerCTR(banners[i].Impressions, banners[i].Clicks)
+		}
+
+		response := map[string]any{
+			"from":    from.Format("2006-01-02"),
+			"to":      to.AddDate(0, 0, -1).Format("2006-01-02"),
+			"banners": banners,
+		}
+
+		// hourly series of a single banner
+		if bannerId := query.Get("banner"); bannerId != "" {
+			type HourCTR struct {
+				Hour        string  `db:"hour" json:"hour"`
+				Impressions int     `db:"impressions" json:"impressions"`
+				Clicks      int     `db:"clicks" json:"clicks"`
+				CTR         float64 `db:"-" json:"ctr"`
+			}
+			var hours []HourCTR
+			params["banner"] = bannerId
+			err := app.DB().NewQuery(`
+				SELECT hour, impressions, clicks
+				FROM banner_stats
+				WHERE banner = {:banner} AND hour >= {:from} AND hour < {:to}
+				ORDER BY hour ASC
+			`).Bind(params).All(&hours)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+			}
+			for i := range hours {
+				hours[i].CTR = bannerCTR(hours[i].Impressions, hours[i].Clicks)
+			}
+			response["hours"] = hours
+		}
+
+		return re.JSON(http.StatusOK, response)
+	}).Bind(apis.RequireSuperuserAuth())
+}
+
+// registerBannerJobs writes the buffered beacons every minute (and on shutdown).
+func registerBannerJobs(app core.App) {
+	app.Cron().MustAdd("flushBannerEvents", "* * * * *", func() {
+		if err := flushBannerEvents(app); err != nil {
+			log.Println("Banner events error:", err)
+		}
+	})
+
+	// keep the known banner ids in sync
+	app.OnRecordAfterCreateSuccess("banners").BindFunc(func(e *core.RecordEvent) error {
+		bannerEvents.setBanner(e.Record.Id, true)
+		return e.Next()
+	})
+	app.OnRecordAfterDeleteSuccess("banners").BindFunc(func(e *core.RecordEvent) error {
+		bannerEvents.setBanner(e.Record.Id, false)
+		return e.Next()
+	})
+
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		if err := flushBannerEvents(e.App); err != nil {
+			log.Println("Banner events error:", err)
+		}
+		return e.Next()
+	})
+}
+
+// bannerViewer identifies who sent a beacon for the per day deduplication.
+func bannerViewer(re *core.RequestEvent) string {
+	if re.Auth != nil {
+		return re.Auth.Id
+	}
+	return "ip:" + re.RealIP()
+}
+
+func bannerCTR(impressions int, clicks int) float64 {
+	if impressions == 0 {
+		return 0
+	}
+	return math.Round(float64(clicks)/float64(impressions)*10000) / 10000
+}
+
+// flushBannerEvents adds the buffered beacons that weren't counted yet for
+// their viewer and day to the hourly banner_stats.
+func flushBannerEvents(app core.App) error {
+	events := bannerEvents.drain()
+	if len(events) == 0 {
+		return nil
+	}
+
+	// beacons for unknown banners are dropped
+	ids := []string{}
+	for event := range events {
+		if !slices.Contains(ids, event.Banner) {
+			ids = append(ids, event.Banner)
+		}
+	}
+	banners, err := app.FindRecordsByIds("banners", ids)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(banners))
+	for _, b := range banners {
+		known[b.Id] = true
+	}
+
+	type statKey struct {
+		Banner string
+		Hour   time.Time
+	}
+
+	err = app.RunInTransaction(func(txApp core.App) error {
+		counts := map[statKey]map[string]int{}
+		for event, hour := range events {
+			if !known[event.Banner] {
+				continue
+			}
+
+			result, err := txApp.DB().NewQuery(`
+				INSERT OR IGNORE INTO banner_events_seen (banner, viewer, day, type)
+				VALUES ({:banner}, {:viewer}, {:day}, {:type})
+			`).Bind(dbx.Params{
+				"banner": event.Banner,
+				"viewer": event.Viewer,
+				"day":    event.Day,
+				"type":   event.Type,
+			}).Execute()
+			if err != nil {
+				return err
+			}
+			if inserted, _ := result.RowsAffected(); inserted == 0 {
+				continue // already counted today
+			}
+
+			key := statKey{Banner: event.Banner, Hour: hour}
+			if counts[key] == nil {
+				counts[key] = map[string]int{}
+			}
+			counts[key][event.Type]++
+		}
+
+		collection, err := txApp.FindCollectionByNameOrId("banner_stats")
+		if err != nil {
+			return err
+		}
+
+		for key, c := range counts {
+			stat, err := txApp.FindFirstRecordByFilter(
+				"banner_stats",
+				"banner = {:banner} && hour = {:hour}",
+				dbx.Params{"banner": key.Banner, "hour": key.Hour.Format(types.DefaultDateLayout)},
+			)
+			if err != nil {
+				stat = core.NewRecord(collection)
+				stat.Set("banner", key.Banner)
+				stat.Set("hour", key.Hour)
+			}
+			stat.Set("impressions+", c["impression"])
+			stat.Set("clicks+", c["click"])
+			if err := txApp.Save(stat); err != nil {
+				return err
+			}
+		}
+
+		// forget the days that can't be counted again anyway
+		cutoff := time.Now().UTC().AddDate(0, 0, -bannerEventsRetentionDays).Format("2006-01-02")
+		_, err = txApp.DB().NewQuery("DELETE FROM banner_events_seen WHERE day < {:cutoff}").
+			Bind(dbx.Params{"cutoff": cutoff}).
+			Execute()
+
+		return err
+	})
+	if err != nil {
+		// put them back for the next run
+		bannerEvents.requeue(events)
+	}
+
+	return err
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func registerCategoryRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Visible Categories with their active game counts
+	e.Router.GET("/api/categories", func(re *core.RequestEvent) error {
+		// a game counts for its main category and for every tag
+		var rows []struct {
+			Id        string `db:"id"`
+			Slug      string `db:"slug"`
+			Names     string `db:"names"`
+			Icon      string `db:"icon"`
+			SortOrder int    `db:"sort_order"`
+			GameCount int    `db:"game_count"`
+		}
+		err := app.DB().NewQuery(`
+			SELECT c.id, c.slug, c.names, c.icon, c.sort_order, (
+				SELECT COUNT(*) FROM games g
+				WHERE g.is_active = 1 AND (
+					g.category = c.id OR
+					EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(g.tags) THEN g.tags ELSE '[]' END) t WHERE t.value = c.id)
+				)
+			) AS game_count
+			FROM game_categories c
+			WHERE c.is_visible = 1
+			ORDER BY c.sort_order ASC, c.slug ASC
+		`).All(&rows)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		type CategoryItem struct {
+			ID        string            `json:"id"`
+			Slug      string            `json:"slug"`
+			Name      string            `json:"name"`
+			Names     map[string]string `json:"names"`
+			Icon      string            `json:"icon"`
+			SortOrder int               `json:"sort_order"`
+			GameCount int               `json:"game_count"`
+		}
+
+		lang := requestLang(re)
+		categories := make([]CategoryItem, 0, len(rows))
+		for _, row := range rows {
+			names := map[string]string{}
+			_ = json.Unmarshal([]byte(row.Names), &names)
+
+			categories = append(categories, CategoryItem{
+				ID:        row.Id,
+				Slug:      row.Slug,
+				Name:      localized(names, lang, row.Slug),
+				Names:     names,
+				Icon:      row.Icon,
+				SortOrder: row.SortOrder,
+				GameCount: row.GameCount,
+			})
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"categories": categories,
+		})
+	})
+}
+
+// categorySlug converts a display name like "Match 3" to its slug ("match-3").
+func categorySlug(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// findCategoryIdsBySlug returns the ids of all game categories keyed by slug.
+func findCategoryIdsBySlug(app core.App) (map[string]string, error) {
+	records, err := app.FindAllRecords("game_categories")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(records))
+	for _, r := range records {
+		ids[r.GetString("slug")] = r.Id
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"log"
+	"net/netip"
+	"path/filepath"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// geoIPDefaultFile is looked up in the data dir when no --geoipDb is given.
+const geoIPDefaultFile = "GeoLite2-Country.mmdb"
+
+// registerCountryHooks sets the country of the new users from their IP, with
+// the MaxMind country database at geoIPPath (read once the flags are parsed).
+// Without the database file the country is only set by the users themselves.
+func registerCountryHooks(app core.App, geoIPPath *string) {
+	var geoIP *geoIPReader
+
+	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
+		path := *geoIPPath
+		if path == "" {
+			path = filepath.Join(app.DataDir(), geoIPDefaultFile)
+		}
+
+		reader, err := openGeoIP(path)
+		if err != nil {
+			log.Println("GeoIP database not loaded, countries won't be detected at signup:", err)
+		} else {
+			geoIP = reader
+		}
+
+		return e.Next()
+	})
+
+	app.OnRecordCreateRequest("users").BindFunc(func(e *core.RecordRequestEvent) error {
+		if geoIP == nil || e.Record.GetString("country") != "" {
+			return e.Next()
+		}
+
+		addr, err := netip.ParseAddr(e.RealIP())
+		if err != nil {
+			return e.Next()
+		}
+
+		country, err := geoIP.Country(addr)
+		if err != nil {
+			log.Println("GeoIP lookup error:", err)
+		}
+		if country != "" {
+			e.Record.Set("country", country)
+		}
+
+		return e.Next()
+	})
+}
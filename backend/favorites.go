@@ -0,0 +1,280 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	favoritesDefaultPerPage = 20
+	favoritesMaxPerPage     = 100
+
+	// the curated featured games are topped up with the most favorited ones
+	featuredMinItems = 6
+)
+
+func registerFavoriteRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Add Game to Favorites (idempotent)
+	e.Router.POST("/api/games/{id}/favorite", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		game, err := app.FindRecordById("games", re.Request.PathValue("id"))
+		if err != nil || !game.GetBool("is_active") {
+			return apiError(re, http.StatusNotFound, "game_not_found", err)
+		}
+
+		existing, _ := app.FindFirstRecordByFilter(
+			"user_favorites",
+			"user = {:user} && game = {:game}",
+			map[string]any{"user": authRecord.Id, "game": game.Id},
+		)
+		if existing == nil {
+			favoritesCollection, err := app.FindCollectionByNameOrId("user_favorites")
+			if err != nil {
+				return err
+			}
+
+			favorite := core.NewRecord(favoritesCollection)
+			favorite.Set("user", authRecord.Id)
+			favorite.Set("game", game.Id)
+			if err := app.Save(favorite); err != nil {
+				return apiError(re, http.StatusBadRequest, "save_failed", err)
+			}
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success":        true,
+			"favorited":      true,
+			"favorite_count": gameFavoriteCount(app, game.Id),
+		})
+	})
+
+	// ROUTE: Remove Game from Favorites (idempotent)
+	e.Router.DELETE("/api/games/{id}/favorite", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		gameId := re.Request.PathValue("id")
+		existing, _ := app.FindFirstRecordByFilter(
+			"user_favorites",
+			"user = {:user} && game = {:game}",
+			map[string]any{"user": authRecord.Id, "game": gameId},
+		)
+		if existing != nil {
+			if err := app.Delete(existing); err != nil {
+				return apiError(re, http.StatusBadRequest, "save_failed", err)
+			}
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success":        true,
+			"favorited":      false,
+			"favorite_count": gameFavoriteCount(app, gameId),
+		})
+	})
+
+	// ROUTE: List own Favorites (?page=&perPage=), most recently added first
+	e.Router.GET("/api/me/favorites", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		query := re.Request.URL.Query()
+		page, _ := strconv.Atoi(query.Get("page"))
+		page = max(page, 1)
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+		if perPage <= 0 {
+			perPage = favoritesDefaultPerPage
+		}
+		perPage = min(perPage, favoritesMaxPerPage)
+
+		totalItems, err := app.CountRecords("user_favorites", dbx.NewExp(
+			"user = {:user} AND game IN (SELECT id FROM games WHERE is_active = 1)",
+			dbx.Params{"user": authRecord.Id},
+		))
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		favorites, err := app.FindRecordsByFilter(
+			"user_favorites",
+			"user = {:user} && game.is_active = true",
+			"-created",
+			perPage, (page-1)*perPage,
+			map[string]any{"user": authRecord.Id},
+		)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		if errs := app.ExpandRecords(favorites, []string{"game", "game.category"}, nil); len(errs) > 0 {
+			log.Println("Favorites expand errors:", errs)
+		}
+
+		items := make([]*core.Record, 0, len(favorites))
+		for _, f := range favorites {
+			if game := f.ExpandedOne("game"); game != nil {
+				items = append(items, game)
+			}
+		}
+		localizeRecords(items, requestLang(re))
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items":      items,
+			"page":       page,
+			"perPage":    perPage,
+			"totalItems": totalItems,
+		})
+	})
+
+	// ROUTE: Featured Games (?lang=&platform=), the curated ones topped up with the most favorited
+	e.Router.GET("/api/games/featured", func(re *core.RequestEvent) error {
+		games, err := featuredGames(app, requestContentTarget(re))
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		localizeRecords(games, requestLang(re))
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items": games,
+		})
+	})
+}
+
+func registerFavoriteHooks(app core.App) {
+	app.OnRecordAfterCreateSuccess("user_favorites").BindFunc(func(e *core.RecordEvent) error {
+		if err := updateGameFavoriteCount(e.App, e.Record.GetString("game")); err != nil {
+			log.Printf("ERROR updating the favorites of game %s: %v", e.Record.GetString("game"), err)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordAfterDeleteSuccess("user_favorites").BindFunc(func(e *core.RecordEvent) error {
+		if err := updateGameFavoriteCount(e.App, e.Record.GetString("game")); err != nil {
+			log.Printf("ERROR updating the favorites of game %s: %v", e.Record.GetString("game"), err)
+		}
+		return e.Next()
+	})
+}
+
+// updateGameFavoriteCount recounts the favorites of a game into games.favorite_count.
+func updateGameFavoriteCount(app core.App, gameId string) error {
+	game, err := app.FindRecordById("games", gameId)
+	if err != nil {
+		// the game itself was deleted (cascade)
+		return nil
+	}
+
+	total, err := app.CountRecords("user_favorites", dbx.HashExp{"game": gameId})
+	if err != nil {
+		return err
+	}
+
+	game.Set("favorite_count", total)
+
+	return app.Save(game)
+}
+
+func gameFavoriteCount(app core.App, gameId string) int {
+	game, err := app.FindRecordById("games", gameId)
+	if err != nil {
+		return 0
+	}
+	return game.GetInt("favorite_count")
+}
+
+// userFavoriteGameIds returns the ids of all games the user added to favorites.
+func userFavoriteGameIds(app core.App, userId string) []string {
+	var ids []string
+	err := app.DB().
+		Select("game").
+		From("user_favorites").
+		Where(dbx.HashExp{"user": userId}).
+		Column(&ids)
+	if err != nil {
+		return nil
+	}
+	return ids
+}
+
+// featuredGames returns the curated featured games live for the target
+// (by priority, then display_order), topped up to featuredMinItems with the
+// most favorited active games.
+func featuredGames(app core.App, target contentTarget) ([]*core.Record, error) {
+	filter, params := scheduledContentFilter(target)
+	featured, err := app.FindRecordsByFilter(
+		"featured_games",
+		filter+" && game_id.is_active = true",
+		"-priority,display_order",
+		0, 0,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, featuredMinItems)
+	for _, f := range featured {
+		if id := f.GetString("game_id"); !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < featuredMinItems {
+		popular, err := app.FindRecordsByFilter(
+			"games",
+			"is_active = true && favorite_count > 0",
+			"-favorite_count,-created",
+			featuredMinItems+len(ids), 0,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range popular {
+			if len(ids) >= featuredMinItems {
+				break
+			}
+			if !slices.Contains(ids, g.Id) {
+				ids = append(ids, g.Id)
+			}
+		}
+	}
+
+	return findGamesInOrder(app, ids)
+}
+
+// findGamesInOrder loads the games (with their category expanded) keeping the order of ids.
+func findGamesInOrder(app core.App, ids []string) ([]*core.Record, error) {
+	records, err := app.FindRecordsByIds("games", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]*core.Record, len(records))
+	for _, r := range records {
+		byId[r.Id] = r
+	}
+
+	games := make([]*core.Record, 0, len(ids))
+	for _, id := range ids {
+		if r, ok := byId[id]; ok {
+			games = append(games, r)
+		}
+	}
+
+	if errs := app.ExpandRecords(games, []string{"category"}, nil); len(errs) > 0 {
+		log.Println("Games expand errors:", errs)
+	}
+
+	return games, nil
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	friendSearchLimit    = 20
+	friendSearchMinChars = 2
+)
+
+// friendRequestSubmission is who a friend request is sent to,
+// by user id or by their referral code.
+type friendRequestSubmission struct {
+	User         string `json:"user"`
+	ReferralCode string `json:"referral_code"`
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern (matched with ESCAPE '\').
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// friendIdsQuery selects the ids of the accepted friends of {:me}.
+const friendIdsQuery = `
+	SELECT CASE WHEN requester = {:me} THEN addressee ELSE requester END
+	FROM friendships
+	WHERE status = 'accepted' AND (requester = {:me} OR addressee = {:me})
+`
+
+func registerFriendRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Friends (accepted friends plus the pending requests both ways)
+	e.Router.GET("/api/friends", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		friendships, err := app.FindRecordsByFilter(
+			"friendships",
+			"requester = {:me} || addressee = {:me}",
+			"-accepted_at,-created",
+			0, 0,
+			dbx.Params{"me": authRecord.Id},
+		)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		if errs := app.ExpandRecords(friendships, []string{"requester", "addressee"}, nil); len(errs) > 0 {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", nil)
+		}
+
+		friends := []map[string]any{}
+		incoming := []map[string]any{}
+		outgoing := []map[string]any{}
+		for _, f := range friendships {
+			other := f.ExpandedOne("requester")
+			if f.GetString("requester") == authRecord.Id {
+				other = f.ExpandedOne("addressee")
+			}
+			if other == nil {
+				continue
+			}
+
+			item := map[string]any{
+				"id":      f.Id,
+				"user":    publicFriendUser(other),
+				"source":  f.GetString("source"),
+				"created": f.GetDateTime("created").String(),
+			}
+
+			switch {
+			case f.GetString("status") == "accepted":
+				item["accepted_at"] = f.GetDateTime("accepted_at").String()
+				friends = append(friends, item)
+			case f.GetString("addressee") == authRecord.Id:
+				incoming = append(incoming, item)
+			default:
+				outgoing = append(outgoing, item)
+			}
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"friends":  friends,
+			"incoming": incoming,
+			"outgoing": outgoing,
+		})
+	})
+
+	// ROUTE: Find Players by Username (?q=, prefix match)
+	e.Router.GET("/api/friends/search", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		q := strings.TrimSpace(re.Request.URL.Query().Get("q"))
+		if len([]rune(q)) < friendSearchMinChars {
+			return apiError(re, http.StatusBadRequest, "missing_search_query", nil)
+		}
+
+		var users []*core.Record
+		err := app.RecordQuery("users").
+			AndWhere(dbx.NewExp(`username LIKE {:prefix} ESCAPE '\'`, dbx.Params{"prefix": likeEscaper.Replace(q) + "%"})).
+			AndWhere(dbx.Not(dbx.HashExp{"id": authRecord.Id})).
+			OrderBy("username ASC").
+			Limit(friendSearchLimit).
+			All(&users)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		items := make([]map[string]any, 0, len(users))
+		for _, u := range users {
+			item := publicFriendUser(u)
+			item["friendship"] = friendshipStatus(app, authRecord.Id, u.Id)
+			items = append(items, item)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items": items,
+		})
+	})
+
+	// ROUTE: Send Friend Request ({"user": id} or {"referral_code": code})
+	// Sending one back to someone who already asked accepts theirs.
+	e.Router.POST("/api/friends/requests", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		var body friendRequestSubmission
+		if err := re.BindBody(&body); err != nil {
+			return apiError(re, http.StatusBadRequest, "invalid_request", err)
+		}
+
+		var other *core.Record
+		var err error
+		switch {
+		case body.User != "":
+			other, err = app.FindRecordById("users", body.User)
+		case body.ReferralCode != "":
+			other, err = app.FindFirstRecordByData("users", "referral_code", strings.TrimSpace(body.ReferralCode))
+		default:
+			return apiError(re, http.StatusBadRequest, "invalid_request", nil)
+		}
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "user_not_found", err)
+		}
+		if other.Id == authRecord.Id {
+			return apiError(re, http.StatusBadRequest, "cannot_friend_yourself", nil)
+		}
+
+		// the lookup and the save in one transaction, so two users asking
+		// each other at the same time end up with a single friendship
+		var friendship *core.Record
+		err = app.RunInTransaction(func(txApp core.App) error {
+			friendship = findFriendship(txApp, authRecord.Id, other.Id)
+			switch {
+			case friendship == nil:
+				var err error
+				friendship, err = saveFriendship(txApp, authRecord.Id, other.Id, "request")
+				return err
+			case friendship.GetString("status") == "pending" && friendship.GetString("addressee") == authRecord.Id:
+				return acceptFriendship(txApp, friendship)
+			}
+			return nil
+		})
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success":    true,
+			"id":         friendship.Id,
+			"status":     friendship.GetString("status"),
+			"user":       publicFriendUser(other),
+			"friendship": friendshipStatus(app, authRecord.Id, other.Id),
+		})
+	})
+
+	// ROUTE: Accept Friend Request (only the addressee can)
+	e.Router.POST("/api/friends/requests/{id}/accept", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		friendship, err := app.FindRecordById("friendships", re.Request.PathValue("id"))
+		if err != nil || friendship.GetString("addressee") != authRecord.Id {
+			return apiError(re, http.StatusNotFound, "friend_request_not_found", err)
+		}
+
+		if friendship.GetString("status") == "pending" {
+			if err := acceptFriendship(app, friendship); err != nil {
+				return apiError(re, http.StatusBadRequest, "save_failed", err)
+			}
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+			"id":      friendship.Id,
+			"status":  friendship.GetString("status"),
+		})
+	})
+
+	// ROUTE: Remove Friend (declines, cancels or unfriends, either user can)
+	e.Router.DELETE("/api/friends/{id}", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		friendship, err := app.FindRecordById("friendships", re.Request.PathValue("id"))
+		if err != nil ||
+			(friendship.GetString("requester") != authRecord.Id && friendship.GetString("addressee") != authRecord.Id) {
+			return apiError(re, http.StatusNotFound, "friend_request_not_found", err)
+		}
+
+		if err := app.Delete(friendship); err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+		})
+	})
+}
+
+// registerFriendHooks records who referred a new user (the `referrer_code`
+// signup param) and makes the two friends right away.
+func registerFriendHooks(app core.App) {
+	app.OnRecordCreateRequest("users").BindFunc(func(e *core.RecordRequestEvent) error {
+		info, err := e.RequestInfo()
+		if err != nil {
+			return err
+		}
+
+		code, _ := info.Body["referrer_code"].(string)
+		code = strings.TrimSpace(code)
+		if code == "" {
+			return e.Next()
+		}
+
+		referrer, err := e.App.FindFirstRecordByData("users", "referral_code", code)
+		if err != nil {
+			return apiError(e.RequestEvent, http.StatusBadRequest, "invalid_referral_code", err)
+		}
+		e.Record.Set("referred_by", referrer.Id)
+
+		return e.Next()
+	})
+
+	// in the same transaction as the new user
+	app.OnRecordCreateExecute("users").BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		referrer := e.Record.GetString("referred_by")
+		if referrer == "" {
+			return nil
+		}
+
+		friendship, err := saveFriendship(e.App, referrer, e.Record.Id, "referral")
+		if err != nil {
+			return err
+		}
+
+		return acceptFriendship(e.App, friendship)
+	})
+}
+
+// publicFriendUser is what users see of each other in the friends lists.
+func publicFriendUser(user *core.Record) map[string]any {
+	name := user.GetString("name")
+	if user.GetBool("hide_name") {
+		name = ""
+	}
+
+	return map[string]any{
+		"id":         user.Id,
+		"username":   user.GetString("username"),
+		"name":       name,
+		"avatar_url": user.GetString("avatar_url"),
+		"level":      user.GetInt("level"),
+	}
+}
+
+// findFriendship returns the friendship between two users, whichever sent
+// the request (nil if there is none).
+func findFriendship(app core.App, userA string, userB string) *core.Record {
+	friendship, _ := app.FindFirstRecordByFilter(
+		"friendships",
+		"(requester = {:a} && addressee = {:b}) || (requester = {:b} && addressee = {:a})",
+		dbx.Params{"a": userA, "b": userB},
+	)
+
+	return friendship
+}
+
+// friendshipStatus describes the friendship between me and other as
+// none, friends, pending_outgoing (me asked) or pending_incoming.
+func friendshipStatus(app core.App, me string, other string) string {
+	friendship := findFriendship(app, me, other)
+	switch {
+	case friendship == nil:
+		return "none"
+	case friendship.GetString("status") == "accepted":
+		return "friends"
+	case friendship.GetString("requester") == me:
+		return "pending_outgoing"
+	}
+
+	return "pending_incoming"
+}
+
+func saveFriendship(app core.App, requester string, addressee string, source string) (*core.Record, error) {
+	collection, err := app.FindCollectionByNameOrId("friendships")
+	if err != nil {
+		return nil, err
+	}
+
+	friendship := core.NewRecord(collection)
+	friendship.Set("requester", requester)
+	friendship.Set("addressee", addressee)
+	friendship.Set("status", "pending")
+	friendship.Set("source", source)
+
+	return friendship, app.Save(friendship)
+}
+
+func acceptFriendship(app core.App, friendship *core.Record) error {
+	friendship.Set("status", "accepted")
+	friendship.Set("accepted_at", time.Now().UTC())
+
+	return app.Save(friendship)
+}
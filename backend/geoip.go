@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/netip"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// geoIPReader looks up countries in a MaxMind DB (.mmdb) file, eg. the
+// GeoLite2-Country or DB-IP country databases.
+type geoIPReader struct {
+	db *maxminddb.Reader
+}
+
+// geoIPRecord is the part of a country database record that is read.
+type geoIPRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+func openGeoIP(path string) (*geoIPReader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &geoIPReader{db: db}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country the address is
+// in (the registered country for anycast or satellite ranges), "" if unknown.
+func (r *geoIPReader) Country(addr netip.Addr) (string, error) {
+	var record geoIPRecord
+	if err := r.db.Lookup(addr.Unmap().AsSlice(), &record); err != nil {
+		return "", err
+	}
+
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode, nil
+	}
+
+	return record.RegisteredCountry.ISOCode, nil
+}
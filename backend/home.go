@@ -0,0 +1,258 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// lucky wheel spins granted every (UTC) day
+	dailySpins = 3
+	// check-in reward when daily_rewards_config has no entry for the day
+	defaultDailyReward = 50
+
+	// recently played games on the home screen
+	continuePlayingLimit = 10
+)
+
+// contentPlatforms are the clients the featured games and banners can be targeted at.
+var contentPlatforms = []string{"ios", "android", "web"}
+
+// contentTarget is who the scheduled home content is selected for.
+type contentTarget struct {
+	Lang     string
+	Platform string // "" when the client didn't tell
+}
+
+// requestContentTarget reads the language (see requestLang) and the platform,
+// from the `platform` query param or the X-Client-Platform header.
+func requestContentTarget(re *core.RequestEvent) contentTarget {
+	platform := re.Request.URL.Query().Get("platform")
+	if platform == "" {
+		platform = re.Request.Header.Get("X-Client-Platform")
+	}
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if !slices.Contains(contentPlatforms, platform) {
+		platform = ""
+	}
+
+	return contentTarget{
+		Lang:     requestLang(re),
+		Platform: platform,
+	}
+}
+
+// scheduledContentFilter matches the active featured_games/banners that are
+// within their starts_at/ends_at window and targeted at the target's locale
+// and platform (items without targeting are shown to everyone).
+func scheduledContentFilter(target contentTarget) (string, map[string]any) {
+	filter := `is_active = true` +
+		` && (starts_at = "" || starts_at <= @now)` +
+		` && (ends_at = "" || ends_at > @now)` +
+		` && (locales:length = 0 || locales:each ?= {:lang})` +
+		` && (platforms:length = 0 || platforms:each ?= {:platform})`
+
+	return filter, map[string]any{
+		"lang":     target.Lang,
+		"platform": target.Platform,
+	}
+}
+
+func registerHomeRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Home Screen (everything the home screen shows, in one response)
+	// Supports If-None-Match, an unchanged home answers 304 without a body.
+	e.Router.GET("/api/home", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		target := requestContentTarget(re)
+		now := time.Now().UTC()
+
+		banners, err := activeBanners(app, target)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		featured, err := featuredGames(app, target)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		continuePlaying, err := continuePlayingGames(app, authRecord.Id)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		localizeRecords(banners, target.Lang)
+		localizeRecords(featured, target.Lang)
+		localizeRecords(continuePlaying, target.Lang)
+
+		_, dailyPlayCap := loadPlayRewardsConfig(app)
+
+		body, err := json.Marshal(map[string]any{
+			"user": map[string]any{
+				"id":            authRecord.Id,
+				"username":      authRecord.GetString("username"),
+				"name":          authRecord.GetString("name"),
+				"avatar_url":    authRecord.GetString("avatar_url"),
+				"level":         authRecord.GetInt("level"),
+				"referral_code": authRecord.GetString("referral_code"),
+				"created":       authRecord.GetDateTime("created").String(),
+			},
+			"wallet": map[string]any{
+				"coins":                authRecord.GetInt("coins"),
+				"play_coins_today":     playCoinsEarnedToday(app, authRecord.Id, now),
+				"play_coins_daily_cap": dailyPlayCap,
+			},
+			"banners":          banners,
+			"featured_games":   featured,
+			"continue_playing": continuePlaying,
+			"check_in":         checkInStatus(app, authRecord, now),
+			"spins_left":       spinsLeftToday(authRecord, now),
+		})
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+		re.Response.Header().Set("ETag", etag)
+		re.Response.Header().Set("Cache-Control", "private, no-cache")
+		re.Response.Header().Add("Vary", "Authorization, Accept-Language, X-Client-Platform")
+
+		if etagMatches(re.Request.Header.Get("If-None-Match"), etag) {
+			return re.NoContent(http.StatusNotModified)
+		}
+
+		return re.Blob(http.StatusOK, "application/json", body)
+	})
+
+	// ROUTE: Banners currently live for the client (?lang=&platform=)
+	e.Router.GET("/api/banners", func(re *core.RequestEvent) error {
+		target := requestContentTarget(re)
+		banners, err := activeBanners(app, target)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		localizeRecords(banners, target.Lang)
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items": banners,
+		})
+	})
+}
+
+// activeBanners returns the banners live for the target, by priority.
+func activeBanners(app core.App, target contentTarget) ([]*core.Record, error) {
+	filter, params := scheduledContentFilter(target)
+
+	return app.FindRecordsByFilter("banners", filter, "-priority,-created", 0, 0, params)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag (or is "*").
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// spinsLeftToday returns the user's lucky wheel spins left, the counter
+// resets to dailySpins (plus the level-up bonus spins) on the first spin
+// of every (UTC) day.
+func spinsLeftToday(user *core.Record, now time.Time) int {
+	lastSpinDate := user.GetDateTime("last_spin_date").Time().UTC()
+	if lastSpinDate.Format("2006-01-02") != now.UTC().Format("2006-01-02") {
+		return dailySpins + user.GetInt("bonus_spins")
+	}
+	return user.GetInt("daily_spins_left")
+}
+
+// dailyRewardAmount returns the check-in reward for a streak (cycling over days 1-7).
+func dailyRewardAmount(app core.App, streak int) int {
+	cycleDay := ((streak - 1) % 7) + 1
+
+	config, err := app.FindFirstRecordByFilter(
+		"daily_rewards_config",
+		"day_number = {:day}",
+		map[string]any{"day": cycleDay},
+	)
+	if err != nil || config == nil {
+		return defaultDailyReward
+	}
+
+	return config.GetInt("reward_amount")
+}
+
+// checkInStatus describes the user's daily check-in: whether today's reward
+// was claimed, the current streak and what the next check-in gives.
+func checkInStatus(app core.App, user *core.Record, now time.Time) map[string]any {
+	todayStr := now.UTC().Format("2006-01-02")
+	lastCheckIn := user.GetDateTime("last_check_in").Time()
+	lastCheckInStr := lastCheckIn.UTC().Format("2006-01-02")
+	streak := currentStreak(user.GetInt("daily_streak"), lastCheckIn, now)
+
+	// today's claim, or tomorrow's when already claimed
+	nextStreak := streak + 1
+
+	return map[string]any{
+		"claimed_today": lastCheckInStr == todayStr,
+		"streak":        streak,
+		"next_day":      ((nextStreak - 1) % 7) + 1,
+		"next_reward":   dailyRewardAmount(app, nextStreak),
+	}
+}
+
+// currentStreak returns the stored daily_streak while it's still running,
+// a missed day breaks it.
+func currentStreak(streak int, lastCheckIn time.Time, now time.Time) int {
+	lastCheckInStr := lastCheckIn.UTC().Format("2006-01-02")
+	if lastCheckInStr == now.UTC().Format("2006-01-02") ||
+		lastCheckInStr == now.UTC().AddDate(0, 0, -1).Format("2006-01-02") {
+		return streak
+	}
+	return 0
+}
+
+// continuePlayingGames returns the active games the user played most recently.
+func continuePlayingGames(app core.App, userId string) ([]*core.Record, error) {
+	var ids []string
+	err := app.DB().
+		Select("game").
+		From("game_sessions").
+		Where(dbx.HashExp{"user": userId}).
+		AndWhere(dbx.NewExp("created >= {:since}", dbx.Params{
+			"since": time.Now().UTC().AddDate(0, -3, 0).Format(types.DefaultDateLayout),
+		})).
+		GroupBy("game").
+		OrderBy("MAX(created) DESC").
+		Limit(continuePlayingLimit).
+		Column(&ids)
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := findGamesInOrder(app, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(games, func(g *core.Record) bool {
+		return !g.GetBool("is_active")
+	}), nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	// also registers the webp decoder, the publishers serve some of the
+	// game art as webp
+	"github.com/chai2010/webp"
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+const (
+	// games mirrored per cron run, the downloads are sequential
+	imageMirrorBatch = 20
+
+	imageVariantJPEGQuality = 82
+	imageVariantWebPQuality = 80
+	// the url stays the same when the mirrored thumbnail changes, so the
+	// image is revalidated (against its ETag, the served file name) hourly
+	imageCacheControl = "public, max-age=3600, stale-while-revalidate=86400"
+	// the full size original served when the variant couldn't be generated
+	imageFallbackCacheControl = "no-cache"
+)
+
+// gameImageWidths are the widths the variants are generated at, a requested
+// width is rounded up to the next one so the storage holds a few per image.
+var gameImageWidths = []int{96, 160, 320, 480, 640, 960}
+
+func registerImageRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Game Image (?w=&format=jpeg|png|webp)
+	// Serves the mirrored thumbnail resized to w (the original without it),
+	// redirects to the hotlinked games.image while it isn't mirrored yet.
+	e.Router.GET("/api/games/{id}/image", func(re *core.RequestEvent) error {
+		game, err := app.FindRecordById("games", re.Request.PathValue("id"))
+		if err != nil || !game.GetBool("is_active") {
+			return apiError(re, http.StatusNotFound, "game_not_found", err)
+		}
+
+		filename := game.GetString("thumbnail")
+		if filename == "" {
+			if url := game.GetString("image"); url != "" {
+				return re.Redirect(http.StatusFound, url)
+			}
+			return apiError(re, http.StatusNotFound, "image_not_found", nil)
+		}
+
+		query := re.Request.URL.Query()
+		width, _ := strconv.Atoi(query.Get("w"))
+		width = max(width, 0)
+
+		format := query.Get("format")
+		if format == "" && strings.Contains(re.Request.Header.Get("Accept"), "image/webp") {
+			format = "webp"
+		}
+		if format != "" && !slices.Contains([]string{"jpeg", "png", "webp"}, format) {
+			return apiError(re, http.StatusBadRequest, "invalid_image_format", nil)
+		}
+
+		fsys, err := app.NewFilesystem()
+		if err != nil {
+			return err
+		}
+		defer fsys.Close()
+
+		cacheControl := imageCacheControl
+		servedPath, servedName, err := gameImageVariant(fsys, game.BaseFilesPath(), filename, width, format)
+		if err != nil {
+			log.Println("Game image variant error:", err)
+			servedPath, servedName = game.BaseFilesPath()+"/"+filename, filename
+			cacheControl = imageFallbackCacheControl
+		}
+
+		// a new thumbnail is a new file name (and so are its variants)
+		re.Response.Header().Set("ETag", `"`+servedName+`"`)
+		re.Response.Header().Set("Cache-Control", cacheControl)
+		re.Response.Header().Add("Vary", "Accept")
+
+		return fsys.Serve(re.Response, re.Request, servedPath, servedName)
+	})
+}
+
+// registerImageJobs mirrors the hotlinked game images into the file storage.
+func registerImageJobs(app core.App) {
+	app.Cron().MustAdd("mirrorGameImages", "45 * * * *", func() {
+		if err := mirrorGameImages(app); err != nil {
+			log.Println("Game images error:", err)
+		}
+	})
+}
+
+// mirrorGameImages downloads the games.image of the games without a
+// thumbnail, or whose image changed since it was mirrored.
+func mirrorGameImages(app core.App) error {
+	// random order, so a few broken urls don't hold back the others
+	games, err := app.FindRecordsByFilter(
+		"games",
+		"image != '' && (thumbnail = '' || thumbnail_source != image)",
+		"@random",
+		imageMirrorBatch, 0,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, game := range games {
+		url := game.GetString("image")
+
+		file, err := downloadThumbnail(url)
+		if err != nil {
+			log.Printf("ERROR mirroring image of game %s: %v", game.Id, err)
+			continue
+		}
+
+		game.Set("thumbnail", file)
+		game.Set("thumbnail_source", url)
+		if err := app.Save(game); err != nil {
+			log.Printf("ERROR mirroring image of game %s: %v", game.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// gameImageVariant returns the storage path and name of the original resized
+// to (the next of gameImageWidths from) width in format, generating it on
+// first use. The variants are kept next to PocketBase's own thumbs so they
+// are deleted together with the original.
+func gameImageVariant(fsys *filesystem.System, baseFilesPath string, filename string, width int, format string) (string, string, error) {
+	originalPath := baseFilesPath + "/" + filename
+	originalFormat := gameImageFormat(filename)
+
+	// a width <= 0 is the original width
+	width = max(width, 0)
+	if width > 0 {
+		i, found := slices.BinarySearch(gameImageWidths, width)
+		if !found {
+			i = min(i, len(gameImageWidths)-1)
+		}
+		width = gameImageWidths[i]
+	}
+
+	if format == "" {
+		// keep the transparency of the png/gif art
+		format = "jpeg"
+		if originalFormat == "png" || originalFormat == "gif" {
+			format = "png"
+		}
+	}
+
+	if width == 0 && format == originalFormat {
+		return originalPath, filename, nil
+	}
+
+	name := fmt.Sprintf("%dw_%s.%s", width, strings.TrimSuffix(filename, filepath.Ext(filename)), format)
+	variantPath := baseFilesPath + "/thumbs_" + filename + "/" + name
+
+	if exists, _ := fsys.Exists(variantPath); exists {
+		return variantPath, name, nil
+	}
+
+	reader, err := fsys.GetReader(originalPath)
+	if err != nil {
+		return "", "", err
+	}
+	defer reader.Close()
+
+	img, err := imaging.Decode(reader, imaging.AutoOrientation(true))
+	if err != nil {
+		return "", "", err
+	}
+
+	// never upscale
+	if width > 0 && width < img.Bounds().Dx() {
+		img = imaging.Resize(img, width, 0, imaging.Lanczos)
+	}
+
+	var buf bytes.Buffer
+	if err := encodeGameImage(&buf, img, format); err != nil {
+		return "", "", err
+	}
+	if err := fsys.Upload(buf.Bytes(), variantPath); err != nil {
+		return "", "", err
+	}
+
+	return variantPath, name, nil
+}
+
+// gameImageFormat is the format of an image file, from its extension.
+func gameImageFormat(filename string) string {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	if format == "jpg" {
+		return "jpeg"
+	}
+	return format
+}
+
+func encodeGameImage(buf *bytes.Buffer, img image.Image, format string) error {
+	switch format {
+	case "png":
+		return imaging.Encode(buf, img, imaging.PNG, imaging.PNGCompressionLevel(png.BestCompression))
+	case "webp":
+		// lossy, with the alpha channel of the png/gif art
+		return webp.Encode(buf, img, &webp.Options{Quality: imageVariantWebPQuality})
+	}
+
+	return imaging.Encode(buf, img, imaging.JPEG, imaging.JPEGQuality(imageVariantJPEGQuality))
+}
@@ -0,0 +1,596 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/spf13/cobra"
+)
+
+const thumbnailDownloadTimeout = 30 * time.Second
+
+// publisherCategories maps the (lowercased) Famobi/GameDistribution
+// categories to our game_categories slugs. Unknown categories fall back to "casual".
+var publisherCategories = map[string]string{
+	"puzzle":         "puzzle",
+	"puzzles":        "puzzle",
+	"match 3":        "match-3",
+	"match-3":        "match-3",
+	"match3":         "match-3",
+	"bubble shooter": "puzzle",
+	"mahjong":        "mahjong",
+	"jigsaw":         "puzzle",
+	"quiz":           "puzzle",
+	"brain":          "puzzle",
+	"action":         "action",
+	"shooter":        "action",
+	"shooting":       "action",
+	"fighting":       "action",
+	"io":             "action",
+	".io":            "action",
+	"strategy":       "strategy",
+	"board":          "board",
+	"cards":          "cards",
+	"card":           "cards",
+	"tower defense":  "strategy",
+	"racing":         "racing",
+	"driving":        "racing",
+	"cars":           "racing",
+	"sports":         "sports",
+	"sport":          "sports",
+	"football":       "sports",
+	"soccer":         "sports",
+	"basketball":     "sports",
+	"adventure":      "adventure",
+	"platformer":     "adventure",
+	"casual":         "casual",
+	"hypercasual":    "casual",
+	"cooking":        "casual",
+	"simulation":     "casual",
+	"dress-up":       "casual",
+	"girls":          "casual",
+	"arcade":         "arcade",
+	"skill":          "arcade",
+	"jump & run":     "arcade",
+}
+
+// feedSourceIdKeys are the id fields the publisher of a feed is detected
+// from (the other feeds need an explicit --source).
+var feedSourceIdKeys = map[string]string{
+	"package_id": "famobi",
+	"md5":        "gamedistribution",
+}
+
+// feedGame is a single catalog entry read from a publisher feed.
+type feedGame struct {
+	// the publisher detected from the row's id field, if any
+	Source      string
+	ExternalId  string
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	Orientation string
+	Categories  []string
+}
+
+type gamesImportOptions struct {
+	// the publisher the feed comes from, the games of the other
+	// publishers are left alone
+	Source         string
+	DryRun         bool
+	SkipThumbnails bool
+	KeepMissing    bool
+	// deactivate the missing games even if the feed had no usable entry
+	Force bool
+}
+
+type gamesImportReport struct {
+	Added       []string
+	Updated     []string
+	Deactivated []string
+	Skipped     []string
+	Warnings    []string
+	Unchanged   int
+}
+
+// newGamesCommand creates the `games` root command (`games import ...`).
+func newGamesCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "games",
+		Short: "Manages the games catalog",
+	}
+
+	command.AddCommand(newGamesImportCommand(app))
+
+	return command
+}
+
+func newGamesImportCommand(app core.App) *cobra.Command {
+	var format string
+	var opts gamesImportOptions
+
+	command := &cobra.Command{
+		Use:          "import [feed file]",
+		Short:        "Imports games from a Famobi/GameDistribution JSON or CSV feed",
+		Example:      "games import ./famobi-feed.json --dry-run",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if err := app.RunAllMigrations(); err != nil {
+				return err
+			}
+
+			entries, skipped, err := readGameFeed(args[0], format)
+			if err != nil {
+				return err
+			}
+
+			if opts.Source == "" {
+				opts.Source, err = detectFeedSource(entries)
+				if err != nil {
+					return err
+				}
+			}
+			opts.Source = strings.ToLower(strings.TrimSpace(opts.Source))
+
+			report, err := importGames(app, entries, opts)
+			if err != nil {
+				return err
+			}
+			report.Skipped = append(skipped, report.Skipped...)
+
+			report.print(command.OutOrStdout(), opts.DryRun)
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&format, "format", "", "feed format (json or csv), detected from the file extension by default")
+	command.Flags().StringVar(&opts.Source, "source", "", "publisher of the feed (eg. famobi or gamedistribution), detected from its id field by default")
+	command.Flags().BoolVar(&opts.DryRun, "dry-run", false, "only report the changes, without saving anything")
+	command.Flags().BoolVar(&opts.SkipThumbnails, "skip-thumbnails", false, "don't download the thumbnails into the file storage")
+	command.Flags().BoolVar(&opts.KeepMissing, "keep-missing", false, "don't deactivate imported games that are no longer in the feed")
+	command.Flags().BoolVar(&opts.Force, "force", false, "deactivate the publisher's games even if the feed has no usable entry")
+
+	return command
+}
+
+// readGameFeed parses the feed file and returns its entries together with
+// a description of the rows that couldn't be used.
+func readGameFeed(path string, format string) ([]feedGame, []string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var rows []map[string]any
+	switch format {
+	case "json":
+		rows, err = readJSONFeedRows(f)
+	case "csv":
+		rows, err = readCSVFeedRows(f)
+	default:
+		return nil, nil, fmt.Errorf("unsupported feed format %q (expected json or csv)", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []feedGame
+	var skipped []string
+	seen := map[string]bool{}
+	for i, row := range rows {
+		entry := feedGameFromRow(row)
+
+		switch {
+		case entry.ExternalId == "":
+			skipped = append(skipped, fmt.Sprintf("entry #%d: missing external id", i+1))
+		case entry.Title == "" || entry.URL == "":
+			skipped = append(skipped, fmt.Sprintf("entry #%d (%s): missing title or url", i+1, entry.ExternalId))
+		case seen[entry.ExternalId]:
+			skipped = append(skipped, fmt.Sprintf("entry #%d (%s): duplicated external id", i+1, entry.ExternalId))
+		default:
+			seen[entry.ExternalId] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, skipped, nil
+}
+
+// detectFeedSource returns the publisher all the entries were detected from.
+func detectFeedSource(entries []feedGame) (string, error) {
+	source := ""
+	for _, entry := range entries {
+		if entry.Source == "" || (source != "" && entry.Source != source) {
+			return "", errors.New("couldn't detect the publisher of the feed, set it with --source")
+		}
+		source = entry.Source
+	}
+
+	if source == "" {
+		return "", errors.New("couldn't detect the publisher of an empty feed, set it with --source")
+	}
+
+	return source, nil
+}
+
+// readJSONFeedRows accepts either a plain array of games or an object
+// wrapping it in a "games", "items" or "data" key.
+func readJSONFeedRows(r io.Reader) ([]map[string]any, error) {
+	var raw any
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid json feed: %w", err)
+	}
+
+	if obj, ok := raw.(map[string]any); ok {
+		for _, key := range []string{"games", "items", "data"} {
+			if list, ok := obj[key]; ok {
+				raw = list
+				break
+			}
+		}
+	}
+
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, errors.New("invalid json feed: expected a list of games")
+	}
+
+	rows := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if row, ok := item.(map[string]any); ok {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+func readCSVFeedRows(r io.Reader) ([]map[string]any, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv feed: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]any, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// feedGameFromRow normalizes the Famobi (package_id, name, thumb, link...)
+// and GameDistribution (Md5, Title, Url, Asset, Category...) field names.
+func feedGameFromRow(row map[string]any) feedGame {
+	normalized := make(map[string]any, len(row))
+	for k, v := range row {
+		normalized[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+
+	entry := feedGame{
+		ExternalId:  feedString(normalized, "external_id", "package_id", "md5", "game_id", "id", "slug"),
+		Title:       feedString(normalized, "title", "name"),
+		Description: feedString(normalized, "description", "short_description"),
+		URL:         feedString(normalized, "url", "link", "game_url"),
+		Image:       feedString(normalized, "thumbnail", "thumb", "image", "thumb_url", "asset", "assets", "images"),
+		Categories:  feedList(normalized, "categories", "category", "genre", "genres"),
+	}
+
+	for key, source := range feedSourceIdKeys {
+		if feedString(normalized, key) != "" {
+			entry.Source = source
+		}
+	}
+
+	orientation := strings.ToLower(feedString(normalized, "orientation", "screen_orientation"))
+	switch {
+	case strings.HasPrefix(orientation, "land"):
+		entry.Orientation = "landscape"
+	case strings.HasPrefix(orientation, "port"):
+		entry.Orientation = "portrait"
+	default:
+		width, _ := strconv.Atoi(feedString(normalized, "width"))
+		height, _ := strconv.Atoi(feedString(normalized, "height"))
+		if width > 0 && height > 0 && width > height {
+			entry.Orientation = "landscape"
+		} else {
+			entry.Orientation = "portrait"
+		}
+	}
+
+	return entry
+}
+
+// feedString returns the first non-empty value of the listed keys
+// (for lists, their first element).
+func feedString(row map[string]any, keys ...string) string {
+	for _, key := range keys {
+		switch v := row[key].(type) {
+		case string:
+			if s := strings.TrimSpace(v); s != "" {
+				return s
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+					return strings.TrimSpace(s)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// feedList returns the values of the first non-empty listed key,
+// splitting strings like "Puzzle, Match 3" or "Puzzle|Match 3".
+func feedList(row map[string]any, keys ...string) []string {
+	for _, key := range keys {
+		var values []string
+		switch v := row[key].(type) {
+		case string:
+			values = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '|' || r == ';' })
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+
+		var result []string
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				result = append(result, value)
+			}
+		}
+		if len(result) > 0 {
+			return result
+		}
+	}
+	return nil
+}
+
+// mapPublisherCategories resolves the publisher categories to our category ids.
+// The first known one becomes the main category and all of them become tags.
+func mapPublisherCategories(categoryIds map[string]string, categories []string) (string, []string) {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, category := range categories {
+		// unknown publisher categories may still match one of ours (eg. a custom one)
+		slug, ok := publisherCategories[strings.ToLower(strings.TrimSpace(category))]
+		if !ok {
+			slug = categorySlug(category)
+		}
+
+		if id, ok := categoryIds[slug]; ok && !seen[id] {
+			seen[id] = true
+			tags = append(tags, id)
+		}
+	}
+
+	if len(tags) == 0 {
+		if id, ok := categoryIds["casual"]; ok {
+			tags = append(tags, id)
+		}
+	}
+
+	if len(tags) == 0 {
+		return "", tags
+	}
+
+	return tags[0], tags
+}
+
+func importGames(app core.App, entries []feedGame, opts gamesImportOptions) (*gamesImportReport, error) {
+	report := &gamesImportReport{}
+
+	gamesCollection, err := app.FindCollectionByNameOrId("games")
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIds, err := findCategoryIdsBySlug(app)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry.ExternalId] = true
+		label := fmt.Sprintf("%s [%s]", entry.Title, entry.ExternalId)
+
+		record, err := findImportedGame(app, opts.Source, entry.ExternalId)
+		if err != nil {
+			return nil, err
+		}
+		isNew := record == nil
+		if isNew {
+			record = core.NewRecord(gamesCollection)
+			record.Set("external_id", entry.ExternalId)
+		}
+
+		imageChanged := record.GetString("image") != entry.Image
+		category, tags := mapPublisherCategories(categoryIds, entry.Categories)
+
+		values := map[string]any{
+			"title":       entry.Title,
+			"description": entry.Description,
+			"url":         entry.URL,
+			"image":       entry.Image,
+			"orientation": entry.Orientation,
+			"category":    category,
+			"tags":        tags,
+			"source":      opts.Source,
+			"is_active":   true,
+		}
+
+		changed := isNew
+		for key, value := range values {
+			if !reflect.DeepEqual(record.Get(key), value) {
+				record.Set(key, value)
+				changed = true
+			}
+		}
+
+		needsThumbnail := !opts.SkipThumbnails && entry.Image != "" &&
+			(imageChanged || record.GetString("thumbnail") == "")
+
+		if !changed && !needsThumbnail {
+			report.Unchanged++
+			continue
+		}
+
+		if !opts.DryRun {
+			if needsThumbnail {
+				if file, err := downloadThumbnail(entry.Image); err != nil {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("%s: thumbnail download failed: %v", label, err))
+				} else {
+					record.Set("thumbnail", file)
+					record.Set("thumbnail_source", entry.Image)
+				}
+			}
+
+			if err := app.Save(record); err != nil {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", label, err))
+				continue
+			}
+		}
+
+		if isNew {
+			report.Added = append(report.Added, label)
+		} else {
+			report.Updated = append(report.Updated, label)
+		}
+	}
+
+	if opts.KeepMissing {
+		return report, nil
+	}
+
+	// a feed that couldn't be read properly (eg. a wrong csv header) would
+	// deactivate the whole catalog of the publisher
+	if len(entries) == 0 && !opts.Force {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"the feed has no usable entry, the %s games were not deactivated (use --force to deactivate them)",
+			opts.Source,
+		))
+		return report, nil
+	}
+
+	// Deactivate the previously imported games of the publisher that are no longer in the feed
+	imported, err := app.FindRecordsByFilter(
+		"games",
+		"source = {:source} && external_id != '' && is_active = true",
+		"title",
+		0, 0,
+		dbx.Params{"source": opts.Source},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range imported {
+		if seen[record.GetString("external_id")] {
+			continue
+		}
+
+		label := fmt.Sprintf("%s [%s]", record.GetString("title"), record.GetString("external_id"))
+		if !opts.DryRun {
+			record.Set("is_active", false)
+			if err := app.Save(record); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: deactivation failed: %v", label, err))
+				continue
+			}
+		}
+		report.Deactivated = append(report.Deactivated, label)
+	}
+
+	return report, nil
+}
+
+// findImportedGame returns the game of the publisher with the external id,
+// or the one imported with it before the games had a source (nil if none).
+func findImportedGame(app core.App, source string, externalId string) (*core.Record, error) {
+	records, err := app.FindRecordsByFilter(
+		"games",
+		"external_id = {:id} && (source = {:source} || source = '')",
+		"-source",
+		1, 0,
+		dbx.Params{"id": externalId, "source": source},
+	)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	return records[0], nil
+}
+
+func downloadThumbnail(url string) (*filesystem.File, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), thumbnailDownloadTimeout)
+	defer cancel()
+
+	return filesystem.NewFileFromURL(ctx, url)
+}
+
+func (r *gamesImportReport) print(w io.Writer, dryRun bool) {
+	section := func(title string, prefix string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "%s (%d):\n", title, len(items))
+		for _, item := range items {
+			fmt.Fprintf(w, "  %s %s\n", prefix, item)
+		}
+	}
+
+	section("Added", "+", r.Added)
+	section("Updated", "~", r.Updated)
+	section("Deactivated", "-", r.Deactivated)
+	section("Skipped", "!", r.Skipped)
+	section("Warnings", "!", r.Warnings)
+
+	fmt.Fprintf(
+		w,
+		"Summary: %d added, %d updated, %d deactivated, %d unchanged, %d skipped",
+		len(r.Added), len(r.Updated), len(r.Deactivated), r.Unchanged, len(r.Skipped),
+	)
+	if dryRun {
+		fmt.Fprint(w, " (dry run, nothing was saved)")
+	}
+	fmt.Fprintln(w)
+}
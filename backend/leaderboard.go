@@ -0,0 +1,393 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	leaderboardDefaultLimit = 50
+	leaderboardMaxLimit     = 100
+	// players above and below the caller in the "around me" mode
+	leaderboardAroundMeSize = 5
+)
+
+// leaderboardPeriods are the windows the coin leaderboards are computed over.
+var leaderboardPeriods = []string{"daily", "weekly", "monthly", "all_time"}
+
+// leaderboardScopes are the player subsets the coin leaderboards rank within.
+var leaderboardScopes = []string{"global", "friends", "country"}
+
+// countryCodeRegex matches the ISO 3166-1 alpha-2 codes of users.country.
+var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// leaderboardFilter limits the standings to some players, the zero value
+// being everyone.
+type leaderboardFilter struct {
+	FriendsOf string // the user and their friends
+	Country   string // the players of a country
+}
+
+// sql returns the condition on the leaderboard_scores rows (aliased as s),
+// to bind with params.
+func (f leaderboardFilter) sql() string {
+	switch {
+	case f.FriendsOf != "":
+		return "AND (s.user = {:me} OR s.user IN (" + friendIdsQuery + "))"
+	case f.Country != "":
+		return "AND s.user IN (SELECT id FROM users WHERE country = {:country})"
+	}
+	return ""
+}
+
+func (f leaderboardFilter) params() dbx.Params {
+	return dbx.Params{"me": f.FriendsOf, "country": f.Country}
+}
+
+// leaderboardRow is a user's standing over a period, with their public profile.
+type leaderboardRow struct {
+	UserId      string         `db:"user_id"`
+	Score       int            `db:"score"`
+	ReachedAt   string         `db:"reached_at"`
+	Username    string         `db:"username"`
+	Name        string         `db:"name"`
+	HideName    bool           `db:"hide_name"`
+	AvatarUrl   string         `db:"avatar_url"`
+	Level       int            `db:"level"`
+	DailyStreak int            `db:"daily_streak"`
+	LastCheckIn types.DateTime `db:"last_check_in"`
+	GamesPlayed int            `db:"games_played"`
+	IsOnline    bool           `db:"is_online"`
+}
+
+func registerLeaderboardRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Coin Leaderboard (?period=daily|weekly|monthly|all_time&scope=global|friends|country&country=&mode=top|around_me&limit=)
+	// Ranks by the coins earned within the period (spending doesn't lower the rank),
+	// "friends" ranks the caller among their friends only, "country" among the
+	// players of a country (the caller's by default) and "around_me" returns
+	// the players just above and below the caller instead of the top.
+	e.Router.GET("/api/leaderboard", func(re *core.RequestEvent) error {
+		query := re.Request.URL.Query()
+
+		period := query.Get("period")
+		if period == "" || period == "all-time" {
+			period = "all_time"
+		}
+
+		now := time.Now().UTC()
+		start, end, ok := leaderboardWindow(period, now)
+		if !ok {
+			return apiError(re, http.StatusBadRequest, "unknown_period", nil)
+		}
+
+		mode := query.Get("mode")
+		if mode == "" {
+			mode = "top"
+		}
+		if mode != "top" && mode != "around_me" {
+			return apiError(re, http.StatusBadRequest, "invalid_leaderboard_mode", nil)
+		}
+
+		scope := query.Get("scope")
+		if scope == "" {
+			scope = "global"
+		}
+		if !slices.Contains(leaderboardScopes, scope) {
+			return apiError(re, http.StatusBadRequest, "invalid_leaderboard_scope", nil)
+		}
+
+		if (mode == "around_me" || scope == "friends") && re.Auth == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		var filter leaderboardFilter
+		switch scope {
+		case "friends":
+			filter.FriendsOf = re.Auth.Id
+		case "country":
+			filter.Country = strings.ToUpper(query.Get("country"))
+			if filter.Country == "" && re.Auth != nil {
+				filter.Country = re.Auth.GetString("country")
+			}
+			if !countryCodeRegex.MatchString(filter.Country) {
+				return apiError(re, http.StatusBadRequest, "unknown_country", nil)
+			}
+		}
+
+		// Current user's score and rank (0 while they earned nothing in the
+		// period or aren't in the scope, eg. another country's leaderboard)
+		var userRank, userScore int
+		if re.Auth != nil {
+			var err error
+			userRank, userScore, err = leaderboardUserRank(app, re.Auth.Id, period, start, filter)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+			}
+		}
+
+		offset := 0
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		if limit <= 0 {
+			limit = leaderboardDefaultLimit
+		}
+		limit = min(limit, leaderboardMaxLimit)
+		if mode == "around_me" {
+			offset = max(0, userRank-1-leaderboardAroundMeSize)
+			limit = userRank - offset + leaderboardAroundMeSize
+			if userRank == 0 {
+				limit = 0
+			}
+		}
+
+		var rows []leaderboardRow
+		if limit > 0 {
+			var err error
+			rows, err = leaderboardStandings(app, period, start, filter, offset, limit)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+			}
+		}
+
+		type LeaderboardItem struct {
+			ID               string `json:"id"`
+			Username         string `json:"username"`
+			Name             string `json:"name"`
+			Avatar           string `json:"avatar"`
+			Coins            int    `json:"coins"`
+			Rank             int    `json:"rank"`
+			Level            int    `json:"level"`
+			DailyStreak      int    `json:"daily_streak"`
+			TotalGamesPlayed int    `json:"total_games_played"`
+			IsOnline         bool   `json:"is_online"`
+		}
+
+		leaderboard := make([]LeaderboardItem, 0, len(rows))
+		for i, row := range rows {
+			// users can opt out of showing their display name
+			name := row.Name
+			if row.HideName {
+				name = ""
+			}
+
+			leaderboard = append(leaderboard, LeaderboardItem{
+				ID:               row.UserId,
+				Username:         row.Username,
+				Name:             name,
+				Avatar:           row.AvatarUrl,
+				Coins:            row.Score,
+				Rank:             offset + i + 1,
+				Level:            row.Level,
+				DailyStreak:      currentStreak(row.DailyStreak, row.LastCheckIn.Time(), now),
+				TotalGamesPlayed: row.GamesPlayed,
+				IsOnline:         row.IsOnline,
+			})
+		}
+
+		response := map[string]any{
+			"period":            period,
+			"scope":             scope,
+			"country":           filter.Country,
+			"mode":              mode,
+			"leaderboard":       leaderboard,
+			"user_rank":         userRank,
+			"user_score":        userScore,
+			"starts_at":         nil,
+			"resets_at":         nil,
+			"resets_in_seconds": 0,
+		}
+		if !end.IsZero() {
+			response["starts_at"] = start.Format(time.RFC3339)
+			response["resets_at"] = end.Format(time.RFC3339)
+			response["resets_in_seconds"] = int(end.Sub(now).Seconds())
+		}
+
+		return re.JSON(http.StatusOK, response)
+	})
+}
+
+// registerLeaderboardHooks keeps the materialized leaderboard_scores in sync
+// with the coin_earnings ledger.
+func registerLeaderboardHooks(app core.App) {
+	// in the same transaction as the ledger entry (and so the coins update)
+	app.OnRecordCreateExecute("coin_earnings").BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		return addLeaderboardScore(e.App, e.Record)
+	})
+
+	app.OnRecordAfterDeleteSuccess("users").BindFunc(func(e *core.RecordEvent) error {
+		_, err := e.App.DB().Delete("leaderboard_scores", dbx.HashExp{"user": e.Record.Id}).Execute()
+		if err != nil {
+			log.Println("Leaderboard scores error:", err)
+		}
+		return e.Next()
+	})
+}
+
+// leaderboardWindow returns the bounds of the period containing t
+// (zero times for all_time) and false for an unknown period.
+func leaderboardWindow(period string, t time.Time) (time.Time, time.Time, bool) {
+	t = t.UTC()
+
+	switch period {
+	case "daily":
+		start := t.Truncate(24 * time.Hour)
+		return start, start.AddDate(0, 0, 1), true
+	case "weekly":
+		start := startOfWeek(t)
+		return start, start.AddDate(0, 0, 7), true
+	case "monthly":
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), true
+	case "all_time":
+		return time.Time{}, time.Time{}, true
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// leaderboardPeriodStart is the period_start key of a window starting at start.
+func leaderboardPeriodStart(start time.Time) string {
+	if start.IsZero() {
+		return "" // all_time
+	}
+	return start.Format(types.DefaultDateLayout)
+}
+
+// addLeaderboardScore adds a coin_earnings entry to the user's score of every
+// period it falls in. The season rewards don't count, the winners start the
+// next season even, and the pre-ledger opening balances only count for all-time.
+func addLeaderboardScore(app core.App, earning *core.Record) error {
+	source := earning.GetString("source")
+	if source == "season_reward" {
+		return nil
+	}
+
+	created := earning.GetDateTime("created").Time()
+	for _, period := range leaderboardPeriods {
+		if period != "all_time" && source == "opening_balance" {
+			continue
+		}
+
+		start, _, _ := leaderboardWindow(period, created)
+		_, err := app.DB().NewQuery(`
+			INSERT INTO leaderboard_scores (period, period_start, user, score, reached_at)
+			VALUES ({:period}, {:start}, {:user}, {:amount}, {:created})
+			ON CONFLICT (period, period_start, user) DO UPDATE SET
+				score = score + excluded.score,
+				reached_at = MAX(reached_at, excluded.reached_at)
+		`).Bind(dbx.Params{
+			"period":  period,
+			"start":   leaderboardPeriodStart(start),
+			"user":    earning.GetString("user"),
+			"amount":  earning.GetInt("amount"),
+			"created": created.Format(types.DefaultDateLayout),
+		}).Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// leaderboardStandings returns limit users from offset of the period starting
+// at start (among the players of filter), the ones that reached their score
+// earlier first on ties. A user is online while they're in a game session
+// that still gets heartbeats.
+func leaderboardStandings(app core.App, period string, start time.Time, filter leaderboardFilter, offset int, limit int) ([]leaderboardRow, error) {
+	params := filter.params()
+	params["period"] = period
+	params["start"] = leaderboardPeriodStart(start)
+	params["limit"] = limit
+	params["offset"] = offset
+	params["onlineSince"] = time.Now().UTC().Add(-heartbeatMaxGap).Format(types.DefaultDateLayout)
+
+	var rows []leaderboardRow
+	err := app.DB().NewQuery(`
+		SELECT
+			s.user AS user_id, s.score, s.reached_at,
+			u.username, u.name, u.hide_name, u.avatar_url, u.level, u.daily_streak, u.last_check_in,
+			(SELECT COUNT(*) FROM game_sessions gs WHERE gs.user = s.user) AS games_played,
+			EXISTS (
+				SELECT 1 FROM game_sessions gs
+				WHERE gs.user = s.user AND gs.status = 'active' AND gs.last_heartbeat_at >= {:onlineSince}
+			) AS is_online
+		FROM leaderboard_scores s
+		JOIN users u ON u.id = s.user
+		WHERE s.period = {:period} AND s.period_start = {:start} ` + filter.sql() + `
+		ORDER BY s.score DESC, s.reached_at ASC, s.user ASC
+		LIMIT {:limit} OFFSET {:offset}
+	`).Bind(params).All(&rows)
+
+	return rows, err
+}
+
+// leaderboardPlayers returns how many users earned coins in the period starting at start.
+func leaderboardPlayers(app core.App, period string, start time.Time) (int, error) {
+	var players int
+	err := app.DB().
+		Select("COUNT(*)").
+		From("leaderboard_scores").
+		Where(dbx.HashExp{"period": period, "period_start": leaderboardPeriodStart(start)}).
+		Row(&players)
+
+	return players, err
+}
+
+// leaderboardUserRank returns the user's rank and score over the period
+// starting at start, with the same ordering and filter as leaderboardStandings
+// (no rank if the user isn't among the filter's players).
+func leaderboardUserRank(app core.App, userId string, period string, start time.Time, filter leaderboardFilter) (int, int, error) {
+	params := filter.params()
+	params["period"] = period
+	params["start"] = leaderboardPeriodStart(start)
+	params["user"] = userId
+
+	var own struct {
+		Score     int    `db:"score"`
+		ReachedAt string `db:"reached_at"`
+	}
+	err := app.DB().NewQuery(`
+		SELECT s.score, s.reached_at FROM leaderboard_scores s
+		WHERE s.period = {:period} AND s.period_start = {:start} AND s.user = {:user} ` + filter.sql() + `
+	`).Bind(params).One(&own)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, nil // nothing earned in the period, or not among the filter's players
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	params["score"] = own.Score
+	params["reachedAt"] = own.ReachedAt
+
+	// (index range scan)
+	var ahead int
+	err = app.DB().NewQuery(`
+		SELECT COUNT(*) FROM leaderboard_scores s
+		WHERE s.period = {:period} AND s.period_start = {:start} AND (
+			s.score > {:score}
+			OR (s.score = {:score} AND s.reached_at < {:reachedAt})
+			OR (s.score = {:score} AND s.reached_at = {:reachedAt} AND s.user < {:user})
+		) ` + filter.sql() + `
+	`).Bind(params).Row(&ahead)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return ahead + 1, own.Score, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const defaultLang = "en"
+
+// supportedLangs are the locales shipped by the app (frontend/assets/locales).
+var supportedLangs = []string{"en", "de", "es", "fr", "fa", "ar", "zh"}
+
+// localizedFields are the fields of each collection that have variants
+// in the record's `translations` json field, eg. {"de": {"title": "..."}}.
+var localizedFields = map[string][]string{
+	"games":        {"title", "description"},
+	"banners":      {"title"},
+	"achievements": {"title", "description"},
+}
+
+// requestLang picks the response language from the `lang` query param
+// or the Accept-Language header, falling back to English.
+func requestLang(re *core.RequestEvent) string {
+	return pickLang(re.Request.URL.Query().Get("lang"), re.Request.Header.Get("Accept-Language"))
+}
+
+func pickLang(langParam string, acceptLanguage string) string {
+	if lang := matchLang(langParam); lang != "" {
+		return lang
+	}
+
+	// eg. "fa-IR,fa;q=0.9,en-US;q=0.8,en;q=0.7"
+	// (the browsers and the RN clients already send them sorted by preference)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if lang := matchLang(tag); lang != "" {
+			return lang
+		}
+	}
+
+	return defaultLang
+}
+
+// matchLang returns the supported language of a tag like "de-AT", or "".
+func matchLang(tag string) string {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	base, _, _ = strings.Cut(base, "_")
+	for _, lang := range supportedLangs {
+		if base == lang {
+			return lang
+		}
+	}
+	return ""
+}
+
+// localized returns the lang variant of a {"en": "...", "de": "..."} map,
+// falling back to English and then to fallback.
+func localized(values map[string]string, lang string, fallback string) string {
+	if v := values[lang]; v != "" {
+		return v
+	}
+	if v := values[defaultLang]; v != "" {
+		return v
+	}
+	return fallback
+}
+
+// localizeRecords replaces the localized fields of the records (and of their
+// expanded relations) with their lang variant, when there is one.
+// The records are only changed in memory, for the response.
+func localizeRecords(records []*core.Record, lang string) {
+	if lang == defaultLang {
+		return
+	}
+
+	for _, record := range records {
+		if fields, ok := localizedFields[record.Collection().Name]; ok {
+			translations := map[string]map[string]string{}
+			_ = record.UnmarshalJSONField("translations", &translations)
+			for _, field := range fields {
+				if v := translations[lang][field]; v != "" {
+					record.Set(field, v)
+				}
+			}
+		}
+
+		for _, expanded := range record.Expand() {
+			switch v := expanded.(type) {
+			case *core.Record:
+				localizeRecords([]*core.Record{v}, lang)
+			case []*core.Record:
+				localizeRecords(v, lang)
+			}
+		}
+	}
+}
+
+// registerLocaleHooks localizes the records served by the collection APIs
+// (the custom routes call localizeRecords themselves).
+func registerLocaleHooks(app core.App) {
+	for collection := range localizedFields {
+		app.OnRecordEnrich(collection).BindFunc(func(e *core.RecordEnrichEvent) error {
+			// superusers edit the originals in the dashboard
+			if e.RequestInfo != nil && !e.RequestInfo.HasSuperuserAuth() {
+				lang := pickLang(e.RequestInfo.Query["lang"], e.RequestInfo.Headers["accept_language"])
+				localizeRecords([]*core.Record{e.Record}, lang)
+			}
+			return e.Next()
+		})
+	}
+}
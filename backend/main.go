@@ -6,9 +6,7 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
-	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
 	_ "mysteryplay-backend/migrations"
@@ -23,6 +21,17 @@ func main() {
 		Automigrate: true,
 	})
 
+	// `games import <feed>` catalog import command
+	app.RootCmd.AddCommand(newGamesCommand(app))
+
+	var geoIPPath string
+	app.RootCmd.PersistentFlags().StringVar(
+		&geoIPPath,
+		"geoipDb",
+		"",
+		"the MaxMind country database (.mmdb) the signup countries are detected with (default pb_data/"+geoIPDefaultFile+")",
+	)
+
 	// ------------------------------------------------------------
 	// HOOK: New User Initialization
 	// ------------------------------------------------------------
@@ -47,8 +56,7 @@ func main() {
 		e.Record.Set("last_spin_date", time.Now().UTC().AddDate(0, 0, -1))
 
 		if e.Record.GetString("avatar_url") == "" {
-			username := e.Record.GetString("username")
-			e.Record.Set("avatar_url", "https://api.dicebear.com/9.x/avataaars/png?seed="+username)
+			e.Record.Set("avatar_url", defaultAvatarURL(e.Record.GetString("username")))
 		}
 
 		// --------------------------------------------------------
@@ -56,6 +64,74 @@ func main() {
 		// --------------------------------------------------------
 	})
 
+	// the starting coins go in the ledger like every later credit (the users
+	// that signed up before the ledger got theirs from its migration)
+	app.OnRecordCreateExecute("users").BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		if e.Record.GetInt("coins") <= 0 {
+			return nil
+		}
+
+		collection, err := e.App.FindCachedCollectionByNameOrId("coin_earnings")
+		if err != nil {
+			return err
+		}
+
+		earning := core.NewRecord(collection)
+		earning.Set("user", e.Record.Id)
+		earning.Set("amount", e.Record.GetInt("coins"))
+		earning.Set("source", "opening_balance")
+
+		return e.App.Save(earning)
+	})
+
+	// ------------------------------------------------------------
+	// HOOKS: Search Index
+	// ------------------------------------------------------------
+	registerSearchHooks(app)
+
+	// ------------------------------------------------------------
+	// HOOKS: Game Rating Aggregates
+	// ------------------------------------------------------------
+	registerRatingHooks(app)
+
+	// ------------------------------------------------------------
+	// HOOKS: Game Favorite Counts
+	// ------------------------------------------------------------
+	registerFavoriteHooks(app)
+
+	// ------------------------------------------------------------
+	// HOOKS: Localized Games / Banners / Achievements
+	// ------------------------------------------------------------
+	registerLocaleHooks(app)
+
+	// ------------------------------------------------------------
+	// HOOKS: Materialized Leaderboards
+	// ------------------------------------------------------------
+	registerLeaderboardHooks(app)
+
+	// ------------------------------------------------------------
+	// HOOKS: Referrals / Friends
+	// ------------------------------------------------------------
+	registerFriendHooks(app)
+
+	// ------------------------------------------------------------
+	// HOOKS: Signup Country (GeoIP)
+	// ------------------------------------------------------------
+	registerCountryHooks(app, &geoIPPath)
+
+	// ------------------------------------------------------------
+	// HOOKS: XP & Levels
+	// ------------------------------------------------------------
+	registerXPHooks(app)
+
+	// ------------------------------------------------------------
+	// HOOKS: Avatar Moderation
+	// ------------------------------------------------------------
+	registerAvatarUploadHooks(app)
+
 	// ------------------------------------------------------------
 	// CUSTOM ROUTES
 	// ------------------------------------------------------------
@@ -65,26 +141,32 @@ func main() {
 			log.Println("Seed error:", err)
 		}
 
+		// rows written outside of the record hooks (migrations, SQL imports)
+		// aren't in the search index, so it is rebuilt on every start
+		if err := rebuildGamesSearchIndex(app); err != nil {
+			log.Println("Search index error:", err)
+		}
+
+		// the play stats are refreshed every 15 minutes, don't wait for the first run
+		if err := computeGameStats(app); err != nil {
+			log.Println("Game stats error:", err)
+		}
+
 		// 1. ROUTE: Play Lucky Spin
 		e.Router.POST("/api/play-lucky-spin", func(re *core.RequestEvent) error {
 			// In v0.23, the auth record is directly on the event
 			authRecord := re.Auth
 			if authRecord == nil {
-				return apis.NewUnauthorizedError("Unauthenticated", nil)
+				return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
 			}
 
 			now := time.Now().UTC()
-			lastSpinDate := authRecord.GetDateTime("last_spin_date").Time().UTC()
-
-			if lastSpinDate.Format("2006-01-02") != now.Format("2006-01-02") {
-				authRecord.Set("daily_spins_left", 3)
-			}
-
-			spinsLeft := authRecord.GetInt("daily_spins_left")
+			spinsLeft := spinsLeftToday(authRecord, now)
 			if spinsLeft <= 0 {
 				return re.JSON(http.StatusOK, map[string]any{
 					"success": false,
-					"message": "No spins left for today!",
+					"code":    "no_spins_left",
+					"message": serverMessage(requestLang(re), "no_spins_left"),
 				})
 			}
 
@@ -111,10 +193,15 @@ func main() {
 
 			reward := selectedPrize.GetInt("value")
 			authRecord.Set("daily_spins_left", spinsLeft-1)
-			authRecord.Set("coins", authRecord.GetInt("coins")+reward)
 			authRecord.Set("last_spin_date", now)
 
-			if err := app.Save(authRecord); err != nil {
+			err = app.RunInTransaction(func(txApp core.App) error {
+				if err := creditCoins(txApp, authRecord, reward, "spin", selectedPrize.Id); err != nil {
+					return err
+				}
+				return grantXP(txApp, authRecord, xpReward(txApp, "spin"), "spin", selectedPrize.Id)
+			})
+			if err != nil {
 				return err
 			}
 
@@ -131,7 +218,7 @@ func main() {
 			// SECURITY CHECK: This replaces apis.RequireAuth() manually
 			authRecord := re.Auth
 			if authRecord == nil {
-				return apis.NewUnauthorizedError("Unauthenticated", nil)
+				return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
 			}
 
 			// 1. Force current time to UTC
@@ -148,7 +235,8 @@ func main() {
 			if lastCheckInStr == todayStr {
 				return re.JSON(http.StatusOK, map[string]any{
 					"success": false,
-					"message": "Already claimed today!",
+					"code":    "already_claimed",
+					"message": serverMessage(requestLang(re), "already_claimed"),
 				})
 			}
 
@@ -160,27 +248,21 @@ func main() {
 			}
 
 			// 5. Look up the reward amount based on the cycle (Day 1-7)
-			cycleDay := ((newStreak - 1) % 7) + 1
-			rewardAmount := 50 // Default fallback
-
-			config, err := app.FindFirstRecordByFilter(
-				"daily_rewards_config",
-				"day_number = {:day}",
-				map[string]any{"day": cycleDay},
-			)
-
-			if err == nil && config != nil {
-				rewardAmount = config.GetInt("reward_amount")
-			}
+			rewardAmount := dailyRewardAmount(app, newStreak)
 
 			// 6. Update the User record
 			authRecord.Set("daily_streak", newStreak)
 			authRecord.Set("last_check_in", now)
-			authRecord.Set("coins", authRecord.GetInt("coins")+rewardAmount)
 
-			// Save the changes back to the database
-			if err := app.Save(authRecord); err != nil {
-				return apis.NewBadRequestError("Failed to update check-in data", err)
+			// Save the changes back to the database (with the ledger entry and the XP)
+			err := app.RunInTransaction(func(txApp core.App) error {
+				if err := creditCoins(txApp, authRecord, rewardAmount, "check_in", ""); err != nil {
+					return err
+				}
+				return grantXP(txApp, authRecord, xpReward(txApp, "check_in"), "check_in", "")
+			})
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "check_in_failed", err)
 			}
 
 			// NEW: Return the updated authRecord (user) in the response
@@ -196,7 +278,7 @@ func main() {
 		e.Router.POST("/api/add-one-spin", func(re *core.RequestEvent) error {
 			authRecord := re.Auth
 			if authRecord == nil {
-				return apis.NewUnauthorizedError("Unauthenticated", nil)
+				return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
 			}
 
 			authRecord.Set("daily_spins_left", authRecord.GetInt("daily_spins_left")+1)
@@ -207,62 +289,73 @@ func main() {
 			return re.JSON(http.StatusOK, map[string]any{"success": true})
 		})
 
-		e.Router.GET("/api/leaderboard", func(re *core.RequestEvent) error {
-			// 1. Get Top 50 Users sorted by coins
-			// Note: Use "coins" as the sorting field.
-			// We use -coins for descending order.
-			records, err := app.FindRecordsByFilter("users", "1=1", "-coins", 50, 0)
-			if err != nil {
-				return apis.NewBadRequestError("Failed to fetch leaderboard", err)
-			}
+		// 4. ROUTES: Game Sessions (play-to-earn)
+		registerSessionRoutes(app, e)
 
-			type LeaderboardItem struct {
-				ID       string `json:"id"`
-				Username string `json:"username"`
-				Avatar   string `json:"avatar"`
-				Coins    int    `json:"coins"`
-				Rank     int    `json:"rank"`
-			}
+		// 5. ROUTES: Game Scores & Per-Game Leaderboards
+		registerScoreRoutes(app, e)
 
-			var leaderboard []LeaderboardItem
-			for i, rec := range records {
-				leaderboard = append(leaderboard, LeaderboardItem{
-					ID:       rec.Id,
-					Username: rec.GetString("username"),
-					Avatar:   rec.GetString("avatar"),
-					Coins:    rec.GetInt("coins"),
-					Rank:     i + 1,
-				})
-			}
+		// 6. ROUTES: Game Categories
+		registerCategoryRoutes(app, e)
 
-			// 2. Logic for the current user's rank (if they aren't in the top 50)
-			var userRank int
-			authRecord := re.Auth
-			if authRecord != nil {
-				userCoins := authRecord.GetInt("coins")
-
-				// SQL trick to get rank: count how many users have more coins than me + 1
-				var count int
-				err := app.DB().
-					Select("count(*)").
-					From("users").
-					Where(dbx.NewExp("coins > {:coins}", dbx.Params{"coins": userCoins})).
-					Row(&count)
-
-				if err == nil {
-					userRank = count + 1
-				}
-			}
+		// 7. ROUTES: Game Search
+		registerSearchRoutes(app, e)
 
-			return re.JSON(http.StatusOK, map[string]any{
-				"leaderboard": leaderboard,
-				"user_rank":   userRank,
-			})
-		})
+		// 8. ROUTES: Game Ratings & Reviews
+		registerRatingRoutes(app, e)
+
+		// 9. ROUTES: Favorites & Featured Games
+		registerFavoriteRoutes(app, e)
+
+		// 10. ROUTES: Personalized Recommendations
+		registerRecommendationRoutes(app, e)
+
+		// 11. ROUTES: Games Catalog (sorted by the play stats) & Trending
+		registerStatsRoutes(app, e)
+
+		// 12. ROUTES: Home Content (scheduled banners)
+		registerHomeRoutes(app, e)
+
+		// 13. ROUTES: Banner Beacons & CTR Report
+		registerBannerRoutes(app, e)
+
+		// 14. ROUTES: Resized Game Images
+		registerImageRoutes(app, e)
+
+		// 15. ROUTES: Coin Leaderboards (daily, weekly, monthly, all-time)
+		registerLeaderboardRoutes(app, e)
+
+		// 16. ROUTES: Past Leaderboard Seasons
+		registerSeasonRoutes(app, e)
+
+		// 17. ROUTES: Friends
+		registerFriendRoutes(app, e)
+
+		// 18. ROUTES: Current User (XP & Levels)
+		registerXPRoutes(app, e)
+
+		// 19. ROUTES: Public Player Profiles
+		registerProfileRoutes(app, e)
+
+		// 20. ROUTES: Generated Avatars
+		registerAvatarRoutes(app, e)
+
+		// 21. ROUTES: Avatar Uploads & Moderation
+		registerAvatarUploadRoutes(app, e)
 
 		return e.Next()
 	})
 
+	// ------------------------------------------------------------
+	// CRON JOBS
+	// ------------------------------------------------------------
+	registerSessionJobs(app)
+	registerRecommendationJobs(app)
+	registerStatsJobs(app)
+	registerBannerJobs(app)
+	registerImageJobs(app)
+	registerSeasonJobs(app)
+
 	if err := app.Start(); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// serverMessages are the user facing messages of the custom routes, by
+// stable code and language. The code is sent along with the message
+// (data.code of the errors), so the clients can also use their own
+// translation of it.
+var serverMessages = map[string]map[string]string{
+	"unauthenticated": {
+		"en": "Please sign in to continue.",
+		"de": "Bitte melde dich an, um fortzufahren.",
+		"es": "Inicia sesión para continuar.",
+		"fr": "Veuillez vous connecter pour continuer.",
+		"fa": "لطفاً برای ادامه وارد شوید.",
+		"ar": "يرجى تسجيل الدخول للمتابعة.",
+		"zh": "请先登录后再继续。",
+	},
+	"no_spins_left": {
+		"en": "No spins left for today!",
+		"de": "Für heute sind keine Drehungen mehr übrig!",
+		"es": "¡No te quedan giros por hoy!",
+		"fr": "Plus de tours disponibles aujourd'hui !",
+		"fa": "امروز دیگر چرخشی باقی نمانده است!",
+		"ar": "لم يتبق لديك أي دورات لهذا اليوم!",
+		"zh": "今天的转盘次数已用完！",
+	},
+	"already_claimed": {
+		"en": "Already claimed today!",
+		"de": "Heute bereits abgeholt!",
+		"es": "¡Ya lo reclamaste hoy!",
+		"fr": "Déjà récupéré aujourd'hui !",
+		"fa": "امروز قبلاً دریافت کرده‌اید!",
+		"ar": "لقد حصلت عليها اليوم بالفعل!",
+		"zh": "今天已经领取过了！",
+	},
+	"check_in_failed": {
+		"en": "Failed to update check-in data.",
+		"de": "Die Check-in-Daten konnten nicht gespeichert werden.",
+		"es": "No se pudieron actualizar los datos de registro diario.",
+		"fr": "Impossible de mettre à jour les données de connexion quotidienne.",
+		"fa": "به‌روزرسانی اطلاعات ورود روزانه ناموفق بود.",
+		"ar": "تعذر تحديث بيانات تسجيل الحضور.",
+		"zh": "签到数据更新失败。",
+	},
+	"fetch_failed": {
+		"en": "Couldn't load the data, please try again.",
+		"de": "Die Daten konnten nicht geladen werden, bitte versuche es erneut.",
+		"es": "No se pudieron cargar los datos, inténtalo de nuevo.",
+		"fr": "Impossible de charger les données, veuillez réessayer.",
+		"fa": "بارگیری اطلاعات ناموفق بود، لطفاً دوباره تلاش کنید.",
+		"ar": "تعذر تحميل البيانات، يرجى المحاولة مرة أخرى.",
+		"zh": "数据加载失败，请重试。",
+	},
+	"save_failed": {
+		"en": "Couldn't save your changes, please try again.",
+		"de": "Deine Änderungen konnten nicht gespeichert werden, bitte versuche es erneut.",
+		"es": "No se pudieron guardar los cambios, inténtalo de nuevo.",
+		"fr": "Impossible d'enregistrer vos modifications, veuillez réessayer.",
+		"fa": "ذخیره تغییرات ناموفق بود، لطفاً دوباره تلاش کنید.",
+		"ar": "تعذر حفظ التغييرات، يرجى المحاولة مرة أخرى.",
+		"zh": "保存失败，请重试。",
+	},
+	"invalid_request": {
+		"en": "Invalid request.",
+		"de": "Ungültige Anfrage.",
+		"es": "Solicitud no válida.",
+		"fr": "Requête invalide.",
+		"fa": "درخواست نامعتبر است.",
+		"ar": "طلب غير صالح.",
+		"zh": "请求无效。",
+	},
+	"game_not_found": {
+		"en": "Game not found.",
+		"de": "Spiel nicht gefunden.",
+		"es": "Juego no encontrado.",
+		"fr": "Jeu introuvable.",
+		"fa": "بازی پیدا نشد.",
+		"ar": "اللعبة غير موجودة.",
+		"zh": "未找到该游戏。",
+	},
+	"image_not_found": {
+		"en": "Image not found.",
+		"de": "Bild nicht gefunden.",
+		"es": "Imagen no encontrada.",
+		"fr": "Image introuvable.",
+		"fa": "تصویر پیدا نشد.",
+		"ar": "الصورة غير موجودة.",
+		"zh": "未找到该图片。",
+	},
+	"invalid_image_format": {
+		"en": "Invalid image format, expected jpeg, png or webp.",
+		"de": "Ungültiges Bildformat, erwartet: jpeg, png oder webp.",
+		"es": "Formato de imagen no válido, se esperaba jpeg, png o webp.",
+		"fr": "Format d'image invalide, valeurs attendues : jpeg, png ou webp.",
+		"fa": "قالب تصویر نامعتبر است، مقادیر مجاز: jpeg، png یا webp.",
+		"ar": "صيغة الصورة غير صالحة، القيم المتوقعة: jpeg أو png أو webp.",
+		"zh": "图片格式无效，可选值为 jpeg、png 或 webp。",
+	},
+	"unknown_category": {
+		"en": "Unknown category.",
+		"de": "Unbekannte Kategorie.",
+		"es": "Categoría desconocida.",
+		"fr": "Catégorie inconnue.",
+		"fa": "دسته‌بندی ناشناخته است.",
+		"ar": "فئة غير معروفة.",
+		"zh": "未知分类。",
+	},
+	"invalid_sort": {
+		"en": "Invalid sort, expected trending, popular, new or rating.",
+		"de": "Ungültige Sortierung, erwartet: trending, popular, new oder rating.",
+		"es": "Orden no válido, se esperaba trending, popular, new o rating.",
+		"fr": "Tri invalide, valeurs attendues : trending, popular, new ou rating.",
+		"fa": "مرتب‌سازی نامعتبر است، مقادیر مجاز: trending، popular، new یا rating.",
+		"ar": "ترتيب غير صالح، القيم المتوقعة: trending أو popular أو new أو rating.",
+		"zh": "排序方式无效，可选值为 trending、popular、new 或 rating。",
+	},
+	"missing_search_query": {
+		"en": "Please enter something to search for.",
+		"de": "Bitte gib einen Suchbegriff ein.",
+		"es": "Escribe algo para buscar.",
+		"fr": "Veuillez saisir un terme à rechercher.",
+		"fa": "لطفاً عبارتی برای جستجو وارد کنید.",
+		"ar": "يرجى إدخال كلمة للبحث.",
+		"zh": "请输入搜索内容。",
+	},
+	"unknown_period": {
+		"en": "Unknown period.",
+		"de": "Unbekannter Zeitraum.",
+		"es": "Periodo desconocido.",
+		"fr": "Période inconnue.",
+		"fa": "بازه زمانی ناشناخته است.",
+		"ar": "فترة غير معروفة.",
+		"zh": "未知的时间范围。",
+	},
+	"invalid_leaderboard_mode": {
+		"en": "Invalid mode, expected top or around_me.",
+		"de": "Ungültiger Modus, erwartet: top oder around_me.",
+		"es": "Modo no válido, se esperaba top o around_me.",
+		"fr": "Mode invalide, valeurs attendues : top ou around_me.",
+		"fa": "حالت نامعتبر است، مقادیر مجاز: top یا around_me.",
+		"ar": "وضع غير صالح، القيم المتوقعة: top أو around_me.",
+		"zh": "模式无效，可选值为 top 或 around_me。",
+	},
+	"season_not_found": {
+		"en": "Season not found.",
+		"de": "Saison nicht gefunden.",
+		"es": "Temporada no encontrada.",
+		"fr": "Saison introuvable.",
+		"fa": "فصل پیدا نشد.",
+		"ar": "الموسم غير موجود.",
+		"zh": "未找到该赛季。",
+	},
+	"invalid_leaderboard_scope": {
+		"en": "Invalid scope, expected global or friends.",
+		"de": "Ungültiger Bereich, erwartet: global oder friends.",
+		"es": "Ámbito no válido, se esperaba global o friends.",
+		"fr": "Portée invalide, valeurs attendues : global ou friends.",
+		"fa": "دامنه نامعتبر است، مقادیر مجاز: global یا friends.",
+		"ar": "نطاق غير صالح، القيم المتوقعة: global أو friends.",
+		"zh": "范围无效，可选值为 global 或 friends。",
+	},
+	"unknown_country": {
+		"en": "Choose your country in your profile to see this leaderboard.",
+		"de": "Wähle dein Land im Profil, um diese Rangliste zu sehen.",
+		"es": "Elige tu país en tu perfil para ver esta clasificación.",
+		"fr": "Choisissez votre pays dans votre profil pour voir ce classement.",
+		"fa": "برای دیدن این جدول رده‌بندی، کشور خود را در پروفایل انتخاب کنید.",
+		"ar": "اختر بلدك في ملفك الشخصي لعرض لوحة المتصدرين هذه.",
+		"zh": "请在个人资料中选择你的国家以查看此排行榜。",
+	},
+	"user_not_found": {
+		"en": "Player not found.",
+		"de": "Spieler nicht gefunden.",
+		"es": "Jugador no encontrado.",
+		"fr": "Joueur introuvable.",
+		"fa": "بازیکن پیدا نشد.",
+		"ar": "لم يتم العثور على اللاعب.",
+		"zh": "未找到该玩家。",
+	},
+	"invalid_referral_code": {
+		"en": "This referral code doesn't exist.",
+		"de": "Dieser Empfehlungscode existiert nicht.",
+		"es": "Este código de referido no existe.",
+		"fr": "Ce code de parrainage n'existe pas.",
+		"fa": "این کد معرف وجود ندارد.",
+		"ar": "رمز الإحالة هذا غير موجود.",
+		"zh": "该邀请码不存在。",
+	},
+	"cannot_friend_yourself": {
+		"en": "You can't add yourself as a friend.",
+		"de": "Du kannst dich nicht selbst als Freund hinzufügen.",
+		"es": "No puedes agregarte a ti mismo como amigo.",
+		"fr": "Vous ne pouvez pas vous ajouter vous-même en ami.",
+		"fa": "نمی‌توانید خودتان را به دوستان اضافه کنید.",
+		"ar": "لا يمكنك إضافة نفسك كصديق.",
+		"zh": "不能添加自己为好友。",
+	},
+	"friend_request_not_found": {
+		"en": "Friend request not found.",
+		"de": "Freundschaftsanfrage nicht gefunden.",
+		"es": "Solicitud de amistad no encontrada.",
+		"fr": "Demande d'ami introuvable.",
+		"fa": "درخواست دوستی پیدا نشد.",
+		"ar": "لم يتم العثور على طلب الصداقة.",
+		"zh": "未找到好友请求。",
+	},
+	"invalid_avatar": {
+		"en": "Invalid avatar, expected a jpeg, png, gif or webp image.",
+		"de": "Ungültiger Avatar, erwartet wird ein jpeg-, png-, gif- oder webp-Bild.",
+		"es": "Avatar no válido, se esperaba una imagen jpeg, png, gif o webp.",
+		"fr": "Avatar invalide, une image jpeg, png, gif ou webp est attendue.",
+		"fa": "آواتار نامعتبر است، یک تصویر jpeg، png، gif یا webp لازم است.",
+		"ar": "الصورة الرمزية غير صالحة، يجب أن تكون صورة jpeg أو png أو gif أو webp.",
+		"zh": "头像无效，需要 jpeg、png、gif 或 webp 图片。",
+	},
+	"avatar_too_large": {
+		"en": "The avatar is too large (5 MB max).",
+		"de": "Der Avatar ist zu groß (max. 5 MB).",
+		"es": "El avatar es demasiado grande (máx. 5 MB).",
+		"fr": "L'avatar est trop volumineux (5 Mo max).",
+		"fa": "آواتار خیلی بزرگ است (حداکثر ۵ مگابایت).",
+		"ar": "الصورة الرمزية كبيرة جدًا (5 ميغابايت كحد أقصى).",
+		"zh": "头像过大（最大 5 MB）。",
+	},
+	"avatar_too_many_pixels": {
+		"en": "The avatar image is too large (4096×4096 pixels max).",
+		"de": "Das Avatarbild ist zu groß (max. 4096×4096 Pixel).",
+		"es": "La imagen del avatar es demasiado grande (máx. 4096×4096 píxeles).",
+		"fr": "L'image de l'avatar est trop grande (4096×4096 pixels max).",
+		"fa": "تصویر آواتار بیش از حد بزرگ است (حداکثر ۴۰۹۶×۴۰۹۶ پیکسل).",
+		"ar": "صورة الأفاتار كبيرة جدًا (الحد الأقصى 4096×4096 بكسل).",
+		"zh": "头像图片过大（最大 4096×4096 像素）。",
+	},
+	"avatar_upload_not_found": {
+		"en": "Avatar upload not found.",
+		"de": "Avatar-Upload nicht gefunden.",
+		"es": "Avatar subido no encontrado.",
+		"fr": "Avatar envoyé introuvable.",
+		"fa": "آواتار بارگذاری‌شده پیدا نشد.",
+		"ar": "لم يتم العثور على الصورة الرمزية المرفوعة.",
+		"zh": "未找到上传的头像。",
+	},
+	"invalid_avatar_upload_status": {
+		"en": "This avatar upload was already reviewed.",
+		"de": "Dieser Avatar-Upload wurde bereits geprüft.",
+		"es": "Este avatar ya fue revisado.",
+		"fr": "Cet avatar a déjà été examiné.",
+		"fa": "این آواتار قبلاً بررسی شده است.",
+		"ar": "تمت مراجعة هذه الصورة الرمزية بالفعل.",
+		"zh": "该头像已审核过。",
+	},
+	"invalid_date": {
+		"en": "Invalid date, expected YYYY-MM-DD.",
+		"de": "Ungültiges Datum, erwartet YYYY-MM-DD.",
+		"es": "Fecha no válida, se esperaba AAAA-MM-DD.",
+		"fr": "Date invalide, format attendu AAAA-MM-JJ.",
+		"fa": "تاریخ نامعتبر است، قالب مورد انتظار YYYY-MM-DD است.",
+		"ar": "تاريخ غير صالح، الصيغة المتوقعة YYYY-MM-DD.",
+		"zh": "日期无效，格式应为 YYYY-MM-DD。",
+	},
+	"too_many_banner_events": {
+		"en": "Too many banner events.",
+		"de": "Zu viele Banner-Ereignisse.",
+		"es": "Demasiados eventos de banner.",
+		"fr": "Trop d'événements de bannière.",
+		"fa": "تعداد رویدادهای بنر بیش از حد مجاز است.",
+		"ar": "عدد أحداث اللافتات كبير جدًا.",
+		"zh": "横幅事件过多。",
+	},
+	"banner_not_found": {
+		"en": "Banner not found.",
+		"de": "Banner nicht gefunden.",
+		"es": "Banner no encontrado.",
+		"fr": "Bannière introuvable.",
+		"fa": "بنر یافت نشد.",
+		"ar": "لم يتم العثور على اللافتة.",
+		"zh": "未找到横幅。",
+	},
+	"rating_out_of_range": {
+		"en": "Rating must be between 1 and 5.",
+		"de": "Die Bewertung muss zwischen 1 und 5 liegen.",
+		"es": "La valoración debe estar entre 1 y 5.",
+		"fr": "La note doit être comprise entre 1 et 5.",
+		"fa": "امتیاز باید بین ۱ تا ۵ باشد.",
+		"ar": "يجب أن يكون التقييم بين 1 و5.",
+		"zh": "评分必须在 1 到 5 之间。",
+	},
+	"rating_not_found": {
+		"en": "Rating not found.",
+		"de": "Bewertung nicht gefunden.",
+		"es": "Valoración no encontrada.",
+		"fr": "Note introuvable.",
+		"fa": "امتیاز پیدا نشد.",
+		"ar": "التقييم غير موجود.",
+		"zh": "未找到该评分。",
+	},
+	"review_not_found": {
+		"en": "Review not found.",
+		"de": "Rezension nicht gefunden.",
+		"es": "Reseña no encontrada.",
+		"fr": "Avis introuvable.",
+		"fa": "نظر پیدا نشد.",
+		"ar": "المراجعة غير موجودة.",
+		"zh": "未找到该评论。",
+	},
+	"cannot_report_own_review": {
+		"en": "You can't report your own review.",
+		"de": "Du kannst deine eigene Rezension nicht melden.",
+		"es": "No puedes denunciar tu propia reseña.",
+		"fr": "Vous ne pouvez pas signaler votre propre avis.",
+		"fa": "نمی‌توانید نظر خودتان را گزارش کنید.",
+		"ar": "لا يمكنك الإبلاغ عن مراجعتك الخاصة.",
+		"zh": "不能举报自己的评论。",
+	},
+	"review_already_reported": {
+		"en": "You already reported this review.",
+		"de": "Du hast diese Rezension bereits gemeldet.",
+		"es": "Ya denunciaste esta reseña.",
+		"fr": "Vous avez déjà signalé cet avis.",
+		"fa": "این نظر را قبلاً گزارش کرده‌اید.",
+		"ar": "لقد أبلغت عن هذه المراجعة بالفعل.",
+		"zh": "你已经举报过这条评论。",
+	},
+	"session_not_found": {
+		"en": "Game session not found.",
+		"de": "Spielsitzung nicht gefunden.",
+		"es": "Sesión de juego no encontrada.",
+		"fr": "Session de jeu introuvable.",
+		"fa": "جلسه بازی پیدا نشد.",
+		"ar": "جلسة اللعب غير موجودة.",
+		"zh": "未找到游戏会话。",
+	},
+	"session_not_active": {
+		"en": "The game session has already ended.",
+		"de": "Die Spielsitzung ist bereits beendet.",
+		"es": "La sesión de juego ya terminó.",
+		"fr": "La session de jeu est déjà terminée.",
+		"fa": "جلسه بازی قبلاً به پایان رسیده است.",
+		"ar": "انتهت جلسة اللعب بالفعل.",
+		"zh": "游戏会话已结束。",
+	},
+	"session_failed": {
+		"en": "Couldn't update the game session, please try again.",
+		"de": "Die Spielsitzung konnte nicht aktualisiert werden, bitte versuche es erneut.",
+		"es": "No se pudo actualizar la sesión de juego, inténtalo de nuevo.",
+		"fr": "Impossible de mettre à jour la session de jeu, veuillez réessayer.",
+		"fa": "به‌روزرسانی جلسه بازی ناموفق بود، لطفاً دوباره تلاش کنید.",
+		"ar": "تعذر تحديث جلسة اللعب، يرجى المحاولة مرة أخرى.",
+		"zh": "游戏会话更新失败，请重试。",
+	},
+	"invalid_score": {
+		"en": "Invalid score.",
+		"de": "Ungültige Punktzahl.",
+		"es": "Puntuación no válida.",
+		"fr": "Score invalide.",
+		"fa": "امتیاز بازی نامعتبر است.",
+		"ar": "نتيجة غير صالحة.",
+		"zh": "分数无效。",
+	},
+	"invalid_score_signature": {
+		"en": "Invalid score signature.",
+		"de": "Ungültige Signatur der Punktzahl.",
+		"es": "Firma de puntuación no válida.",
+		"fr": "Signature du score invalide.",
+		"fa": "امضای امتیاز نامعتبر است.",
+		"ar": "توقيع النتيجة غير صالح.",
+		"zh": "分数签名无效。",
+	},
+	"score_timestamp_out_of_range": {
+		"en": "Score timestamp is out of range.",
+		"de": "Der Zeitstempel der Punktzahl liegt außerhalb des gültigen Bereichs.",
+		"es": "La marca de tiempo de la puntuación está fuera de rango.",
+		"fr": "L'horodatage du score est hors limites.",
+		"fa": "زمان ثبت امتیاز خارج از محدوده مجاز است.",
+		"ar": "الطابع الزمني للنتيجة خارج النطاق المسموح.",
+		"zh": "分数时间戳超出范围。",
+	},
+	"score_already_submitted": {
+		"en": "Score was already submitted.",
+		"de": "Die Punktzahl wurde bereits übermittelt.",
+		"es": "La puntuación ya fue enviada.",
+		"fr": "Le score a déjà été envoyé.",
+		"fa": "این امتیاز قبلاً ثبت شده است.",
+		"ar": "تم إرسال النتيجة بالفعل.",
+		"zh": "该分数已提交。",
+	},
+	"too_many_scores": {
+		"en": "Too many scores for this session.",
+		"de": "Zu viele Punktzahlen für diese Sitzung.",
+		"es": "Demasiadas puntuaciones para esta sesión.",
+		"fr": "Trop de scores pour cette session.",
+		"fa": "تعداد امتیازهای این جلسه بیش از حد مجاز است.",
+		"ar": "عدد النتائج لهذه الجلسة كبير جدًا.",
+		"zh": "本次会话提交的分数过多。",
+	},
+}
+
+// serverMessage returns the lang message of a code (English when there
+// is no translation, the code itself when it isn't in the catalog).
+func serverMessage(lang string, code string) string {
+	return localized(serverMessages[code], lang, code)
+}
+
+// apiError is an ApiError with the code's message in the request language
+// and the code itself in data.code, eg.
+// {"status": 400, "message": "...", "data": {"code": "invalid_score"}}.
+func apiError(re *core.RequestEvent, status int, code string, err error) *router.ApiError {
+	apiErr := router.NewApiError(status, "", err)
+	// set as is, the default sentenizing doesn't know about "。" and "！"
+	apiErr.Message = serverMessage(requestLang(re), code)
+	apiErr.Data["code"] = code
+
+	return apiErr
+}
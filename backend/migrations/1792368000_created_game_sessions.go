@@ -0,0 +1,99 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// per-game payout multiplier (0 is treated as 1 by the reward logic)
+		games.Fields.Add(&core.NumberField{
+			Name: "reward_multiplier",
+			Min:  types.Pointer(0.0),
+		})
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		// 1. game_sessions
+		sessions := core.NewBaseCollection("game_sessions")
+		sessions.ListRule = types.Pointer("user = @request.auth.id")
+		sessions.ViewRule = types.Pointer("user = @request.auth.id")
+		sessions.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "game",
+				CollectionId:  games.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.SelectField{
+				Name:      "status",
+				MaxSelect: 1,
+				Values:    []string{"active", "ended", "flagged"},
+			},
+			&core.DateField{Name: "started_at"},
+			&core.DateField{Name: "last_heartbeat_at"},
+			&core.DateField{Name: "ended_at"},
+			// server receive times (unix ms) of every accepted heartbeat
+			&core.JSONField{Name: "heartbeats", Hidden: true},
+			&core.NumberField{Name: "verified_seconds", OnlyInt: true},
+			&core.NumberField{Name: "coins_earned", OnlyInt: true},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		sessions.AddIndex("idx_game_sessions_user_status", false, "user, status", "")
+		sessions.AddIndex("idx_game_sessions_user_ended_at", false, "user, ended_at", "")
+		if err := app.Save(sessions); err != nil {
+			return err
+		}
+
+		// 2. play_rewards_config (single row, edited from the Dashboard)
+		config := core.NewBaseCollection("play_rewards_config")
+		config.ListRule = types.Pointer("")
+		config.ViewRule = types.Pointer("")
+		config.Fields.Add(
+			&core.NumberField{Name: "coins_per_minute", Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "daily_cap", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		return app.Save(config)
+	}, func(app core.App) error {
+		for _, name := range []string{"game_sessions", "play_rewards_config"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+			if err := app.Delete(collection); err != nil {
+				return err
+			}
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+		games.Fields.RemoveByName("reward_multiplier")
+		return app.Save(games)
+	})
+}
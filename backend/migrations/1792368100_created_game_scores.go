@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		sessions, err := app.FindCollectionByNameOrId("game_sessions")
+		if err != nil {
+			return err
+		}
+
+		// per-session secret used to HMAC sign the score submissions
+		sessions.Fields.Add(&core.TextField{
+			Name:   "score_key",
+			Hidden: true,
+		})
+		if err := app.Save(sessions); err != nil {
+			return err
+		}
+
+		scores := core.NewBaseCollection("game_scores")
+		scores.ListRule = types.Pointer("user = @request.auth.id")
+		scores.ViewRule = types.Pointer("user = @request.auth.id")
+		scores.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "game",
+				CollectionId:  games.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "session",
+				CollectionId:  sessions.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.NumberField{Name: "score", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "level", OnlyInt: true, Min: types.Pointer(0.0)},
+			// client timestamp (unix ms) that was signed, used against replays
+			&core.NumberField{Name: "client_ts", OnlyInt: true, Hidden: true},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		scores.AddIndex("idx_game_scores_game_score", false, "game, score", "")
+		scores.AddIndex("idx_game_scores_game_user", false, "game, user", "")
+		scores.AddIndex("idx_game_scores_session", false, "session, client_ts", "")
+
+		return app.Save(scores)
+	}, func(app core.App) error {
+		scores, err := app.FindCollectionByNameOrId("game_scores")
+		if err != nil {
+			return err
+		}
+		if err := app.Delete(scores); err != nil {
+			return err
+		}
+
+		sessions, err := app.FindCollectionByNameOrId("game_sessions")
+		if err != nil {
+			return err
+		}
+		sessions.Fields.RemoveByName("score_key")
+		return app.Save(sessions)
+	})
+}
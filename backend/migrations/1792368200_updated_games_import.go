@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// stable publisher id used by `games import` to upsert the feed entries
+		games.Fields.Add(&core.TextField{Name: "external_id"})
+		games.Fields.Add(&core.FileField{
+			Name:      "thumbnail",
+			MaxSelect: 1,
+			MaxSize:   5 << 20,
+			MimeTypes: []string{"image/jpeg", "image/png", "image/webp", "image/gif"},
+		})
+		games.AddIndex("idx_games_external_id", true, "external_id", "external_id != ''")
+
+		return app.Save(games)
+	}, func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		games.RemoveIndex("idx_games_external_id")
+		games.Fields.RemoveByName("external_id")
+		games.Fields.RemoveByName("thumbnail")
+
+		return app.Save(games)
+	})
+}
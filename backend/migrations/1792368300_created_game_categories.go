@@ -0,0 +1,165 @@
+package migrations
+
+import (
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// default categories: the old select values + the ones used by the seeds
+var defaultGameCategories = []map[string]any{
+	{"slug": "puzzle", "icon": "puzzle", "names": map[string]string{"en": "Puzzle", "de": "Rätsel", "es": "Puzle", "fr": "Puzzle", "fa": "پازل", "ar": "ألغاز", "zh": "益智"}},
+	{"slug": "action", "icon": "sword", "names": map[string]string{"en": "Action", "de": "Action", "es": "Acción", "fr": "Action", "fa": "اکشن", "ar": "أكشن", "zh": "动作"}},
+	{"slug": "strategy", "icon": "chess-rook", "names": map[string]string{"en": "Strategy", "de": "Strategie", "es": "Estrategia", "fr": "Stratégie", "fa": "استراتژی", "ar": "استراتيجية", "zh": "策略"}},
+	{"slug": "racing", "icon": "flag-checkered", "names": map[string]string{"en": "Racing", "de": "Rennen", "es": "Carreras", "fr": "Course", "fa": "مسابقه‌ای", "ar": "سباق", "zh": "竞速"}},
+	{"slug": "sports", "icon": "soccer", "names": map[string]string{"en": "Sports", "de": "Sport", "es": "Deportes", "fr": "Sport", "fa": "ورزشی", "ar": "رياضة", "zh": "体育"}},
+	{"slug": "adventure", "icon": "compass", "names": map[string]string{"en": "Adventure", "de": "Abenteuer", "es": "Aventura", "fr": "Aventure", "fa": "ماجراجویی", "ar": "مغامرة", "zh": "冒险"}},
+	{"slug": "casual", "icon": "gamepad-variant", "names": map[string]string{"en": "Casual", "de": "Casual", "es": "Casual", "fr": "Casual", "fa": "سرگرمی", "ar": "عادية", "zh": "休闲"}},
+	{"slug": "arcade", "icon": "controller-classic", "names": map[string]string{"en": "Arcade", "de": "Arcade", "es": "Arcade", "fr": "Arcade", "fa": "آرکید", "ar": "أركيد", "zh": "街机"}},
+	{"slug": "cards", "icon": "cards-playing", "names": map[string]string{"en": "Cards", "de": "Karten", "es": "Cartas", "fr": "Cartes", "fa": "کارتی", "ar": "ورق", "zh": "纸牌"}},
+	{"slug": "match-3", "icon": "diamond-stone", "names": map[string]string{"en": "Match 3", "de": "Match 3", "es": "Combina 3", "fr": "Match 3", "fa": "جورچین", "ar": "طابق 3", "zh": "三消"}},
+	{"slug": "mahjong", "icon": "mahjong", "names": map[string]string{"en": "Mahjong", "de": "Mahjong", "es": "Mahjong", "fr": "Mahjong", "fa": "ماهجونگ", "ar": "ماجونغ", "zh": "麻将"}},
+	{"slug": "board", "icon": "checkerboard", "names": map[string]string{"en": "Board", "de": "Brettspiele", "es": "Tablero", "fr": "Plateau", "fa": "رومیزی", "ar": "ألواح", "zh": "棋盘"}},
+}
+
+func init() {
+	m.Register(func(app core.App) error {
+		// 1. game_categories
+		categories := core.NewBaseCollection("game_categories")
+		categories.ListRule = types.Pointer("is_visible = true")
+		categories.ViewRule = types.Pointer("is_visible = true")
+		categories.Fields.Add(
+			&core.TextField{Name: "slug", Required: true, Pattern: "^[a-z0-9-]+$", Presentable: true},
+			// localized display names, eg. {"en": "Puzzle", "de": "Rätsel"}
+			&core.JSONField{Name: "names"},
+			&core.TextField{Name: "icon"},
+			&core.NumberField{Name: "sort_order", OnlyInt: true},
+			&core.BoolField{Name: "is_visible"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		categories.AddIndex("idx_game_categories_slug", true, "slug", "")
+		if err := app.Save(categories); err != nil {
+			return err
+		}
+
+		categoryIds := map[string]string{}
+		for i, data := range defaultGameCategories {
+			record := core.NewRecord(categories)
+			record.Load(data)
+			record.Set("sort_order", (i+1)*10)
+			record.Set("is_visible", true)
+			if err := app.Save(record); err != nil {
+				return err
+			}
+			categoryIds[record.GetString("slug")] = record.Id
+		}
+
+		// 2. remember the current select values before replacing the field
+		var rows []struct {
+			Id       string `db:"id"`
+			Category string `db:"category"`
+		}
+		if err := app.DB().Select("id", "category").From("games").All(&rows); err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		games.Fields.RemoveByName("category")
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		games.Fields.Add(
+			&core.RelationField{
+				Name:         "category",
+				CollectionId: categories.Id,
+				MaxSelect:    1,
+			},
+			&core.RelationField{
+				Name:         "tags",
+				CollectionId: categories.Id,
+				MaxSelect:    99,
+			},
+		)
+		games.AddIndex("idx_games_category", false, "category", "")
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		// 3. link the existing games to their new category
+		for _, row := range rows {
+			slug := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(row.Category)), " ", "-")
+			categoryId, ok := categoryIds[slug]
+			if !ok {
+				continue
+			}
+
+			_, err := app.DB().Update(
+				"games",
+				dbx.Params{"category": categoryId, "tags": `["` + categoryId + `"]`},
+				dbx.HashExp{"id": row.Id},
+			).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		var rows []struct {
+			Id   string `db:"id"`
+			Name string `db:"name"`
+		}
+		err := app.DB().
+			Select("games.id AS id", "json_extract(c.names, '$.en') AS name").
+			From("games").
+			InnerJoin("game_categories c", dbx.NewExp("c.id = games.category")).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		games.RemoveIndex("idx_games_category")
+		games.Fields.RemoveByName("category")
+		games.Fields.RemoveByName("tags")
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		games.Fields.Add(&core.SelectField{
+			Name:      "category",
+			MaxSelect: 1,
+			Values:    []string{"puzzle", "Action", "Strategy", "Racing", "Sports", "Adventure", "Casual", "Arcade"},
+		})
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			_, err := app.DB().Update("games", dbx.Params{"category": row.Name}, dbx.HashExp{"id": row.Id}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		categories, err := app.FindCollectionByNameOrId("game_categories")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(categories)
+	})
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// localized variants, eg. {"de": {"title": "...", "description": "..."}}
+		games.Fields.Add(&core.JSONField{Name: "translations"})
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		// full-text index over the games catalog, kept in sync by the record hooks
+		// (see search.go) and rebuilt on start when it gets out of sync
+		_, err = app.DB().NewQuery(`
+			CREATE VIRTUAL TABLE IF NOT EXISTS games_fts USING fts5(
+				game_id UNINDEXED,
+				title,
+				description,
+				category,
+				localized,
+				tokenize = 'unicode61 remove_diacritics 2',
+				prefix = '2 3'
+			)
+		`).Execute()
+		if err != nil {
+			return err
+		}
+
+		// all indexed terms, used to correct the typos in the search queries
+		_, err = app.DB().NewQuery(`
+			CREATE VIRTUAL TABLE IF NOT EXISTS games_fts_vocab USING fts5vocab(games_fts, 'row')
+		`).Execute()
+
+		return err
+	}, func(app core.App) error {
+		if _, err := app.DB().NewQuery("DROP TABLE IF EXISTS games_fts_vocab").Execute(); err != nil {
+			return err
+		}
+		if _, err := app.DB().NewQuery("DROP TABLE IF EXISTS games_fts").Execute(); err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+		games.Fields.RemoveByName("translations")
+
+		return app.Save(games)
+	})
+}
@@ -0,0 +1,118 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// aggregates maintained by the game_ratings hooks (see ratings.go)
+		games.Fields.Add(
+			&core.NumberField{Name: "rating_avg", Min: types.Pointer(0.0), Max: types.Pointer(5.0)},
+			&core.NumberField{Name: "rating_count", OnlyInt: true, Min: types.Pointer(0.0)},
+		)
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		// 1. game_ratings
+		ratings := core.NewBaseCollection("game_ratings")
+		// the star ratings are public through the aggregates,
+		// the reviews only once a moderator approved them
+		ratings.ListRule = types.Pointer("review_status = 'approved' || user = @request.auth.id")
+		ratings.ViewRule = types.Pointer("review_status = 'approved' || user = @request.auth.id")
+		ratings.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "game",
+				CollectionId:  games.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.NumberField{
+				Name:     "rating",
+				OnlyInt:  true,
+				Min:      types.Pointer(1.0),
+				Max:      types.Pointer(5.0),
+				Required: true,
+			},
+			&core.TextField{Name: "review", Max: 1000},
+			&core.SelectField{
+				Name:      "review_status",
+				MaxSelect: 1,
+				Values:    []string{"pending", "approved", "rejected"},
+			},
+			&core.NumberField{Name: "report_count", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		ratings.AddIndex("idx_game_ratings_user_game", true, "user, game", "")
+		ratings.AddIndex("idx_game_ratings_game_status", false, "game, review_status", "")
+		if err := app.Save(ratings); err != nil {
+			return err
+		}
+
+		// 2. game_review_reports (one report per user per review)
+		reports := core.NewBaseCollection("game_review_reports")
+		reports.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "rating",
+				CollectionId:  ratings.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.TextField{Name: "reason", Max: 500},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		reports.AddIndex("idx_game_review_reports_user_rating", true, "user, rating", "")
+
+		return app.Save(reports)
+	}, func(app core.App) error {
+		for _, name := range []string{"game_review_reports", "game_ratings"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+			if err := app.Delete(collection); err != nil {
+				return err
+			}
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+		games.Fields.RemoveByName("rating_avg")
+		games.Fields.RemoveByName("rating_count")
+
+		return app.Save(games)
+	})
+}
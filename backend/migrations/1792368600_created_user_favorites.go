@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// maintained by the user_favorites hooks (see favorites.go)
+		games.Fields.Add(&core.NumberField{Name: "favorite_count", OnlyInt: true, Min: types.Pointer(0.0)})
+		if err := app.Save(games); err != nil {
+			return err
+		}
+
+		favorites := core.NewBaseCollection("user_favorites")
+		favorites.ListRule = types.Pointer("user = @request.auth.id")
+		favorites.ViewRule = types.Pointer("user = @request.auth.id")
+		favorites.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "game",
+				CollectionId:  games.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		favorites.AddIndex("idx_user_favorites_user_game", true, "user, game", "")
+		favorites.AddIndex("idx_user_favorites_game_created", false, "game, created", "")
+
+		return app.Save(favorites)
+	}, func(app core.App) error {
+		favorites, err := app.FindCollectionByNameOrId("user_favorites")
+		if err != nil {
+			return err
+		}
+		if err := app.Delete(favorites); err != nil {
+			return err
+		}
+
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+		games.Fields.RemoveByName("favorite_count")
+
+		return app.Save(games)
+	})
+}
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// item-to-item co-occurrence of the games, rebuilt by the
+		// computeGameSimilarities cron job (see recommendations.go)
+		similarities := core.NewBaseCollection("game_similarities")
+		similarities.Fields.Add(
+			&core.RelationField{
+				Name:          "game",
+				CollectionId:  games.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "similar_game",
+				CollectionId:  games.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			// cosine similarity of the sets of players, 0-1
+			&core.NumberField{Name: "score"},
+			// players who played both games
+			&core.NumberField{Name: "co_players", OnlyInt: true},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		similarities.AddIndex("idx_game_similarities_pair", true, "game, similar_game", "")
+
+		return app.Save(similarities)
+	}, func(app core.App) error {
+		similarities, err := app.FindCollectionByNameOrId("game_similarities")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(similarities)
+	})
+}
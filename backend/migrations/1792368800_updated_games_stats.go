@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// gameStatsFields are computed from the game sessions by the computeGameStats cron job (see stats.go).
+var gameStatsFields = []string{
+	"play_count",
+	"unique_players",
+	"avg_session_seconds",
+	"plays_24h",
+	"plays_7d",
+	"trending_24h",
+	"trending_7d",
+	"trending_score",
+	"stats_updated_at",
+}
+
+func init() {
+	m.Register(func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		games.Fields.Add(
+			&core.NumberField{Name: "play_count", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "unique_players", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "avg_session_seconds", Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "plays_24h", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "plays_7d", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "trending_24h", Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "trending_7d", Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "trending_score", Min: types.Pointer(0.0)},
+			&core.DateField{Name: "stats_updated_at"},
+		)
+
+		// catalog sorts
+		games.AddIndex("idx_games_trending_score", false, "is_active, trending_score", "")
+		games.AddIndex("idx_games_play_count", false, "is_active, play_count", "")
+		games.AddIndex("idx_games_rating_avg", false, "is_active, rating_avg", "")
+
+		return app.Save(games)
+	}, func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		games.RemoveIndex("idx_games_trending_score")
+		games.RemoveIndex("idx_games_play_count")
+		games.RemoveIndex("idx_games_rating_avg")
+		for _, name := range gameStatsFields {
+			games.Fields.RemoveByName(name)
+		}
+
+		return app.Save(games)
+	})
+}
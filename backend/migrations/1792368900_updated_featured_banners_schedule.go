@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// only the items within their starts_at/ends_at window are public,
+// the locale and platform targeting is applied by the home routes (see home.go)
+const scheduledContentRule = `is_active = true && (starts_at = "" || starts_at <= @now) && (ends_at = "" || ends_at > @now)`
+
+func init() {
+	m.Register(func(app core.App) error {
+		for _, name := range []string{"featured_games", "banners"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+
+			collection.Fields.Add(
+				&core.DateField{Name: "starts_at"},
+				&core.DateField{Name: "ends_at"},
+				// empty = every locale / platform
+				&core.SelectField{
+					Name:      "locales",
+					MaxSelect: 7,
+					Values:    []string{"en", "de", "es", "fr", "fa", "ar", "zh"},
+				},
+				&core.SelectField{
+					Name:      "platforms",
+					MaxSelect: 3,
+					Values:    []string{"ios", "android", "web"},
+				},
+				// higher first
+				&core.NumberField{Name: "priority", OnlyInt: true},
+			)
+			collection.ListRule = types.Pointer(scheduledContentRule)
+			collection.ViewRule = types.Pointer(scheduledContentRule)
+			collection.AddIndex("idx_"+name+"_schedule", false, "is_active, starts_at, ends_at", "")
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, name := range []string{"featured_games", "banners"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+
+			collection.RemoveIndex("idx_" + name + "_schedule")
+			for _, field := range []string{"starts_at", "ends_at", "locales", "platforms", "priority"} {
+				collection.Fields.RemoveByName(field)
+			}
+			collection.ListRule = types.Pointer("")
+			collection.ViewRule = types.Pointer("")
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
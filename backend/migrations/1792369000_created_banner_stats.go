@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		banners, err := app.FindCollectionByNameOrId("banners")
+		if err != nil {
+			return err
+		}
+
+		// hourly impressions and clicks per banner, both deduplicated per viewer and day
+		stats := core.NewBaseCollection("banner_stats")
+		stats.Fields.Add(
+			&core.RelationField{
+				Name:          "banner",
+				CollectionId:  banners.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.DateField{Name: "hour", Required: true},
+			&core.NumberField{Name: "impressions", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "clicks", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		stats.AddIndex("idx_banner_stats_banner_hour", true, "banner, hour", "")
+		if err := app.Save(stats); err != nil {
+			return err
+		}
+
+		// the (banner, viewer, day, type) already counted, pruned after a few days
+		_, err = app.DB().NewQuery(`
+			CREATE TABLE IF NOT EXISTS banner_events_seen (
+				banner TEXT NOT NULL,
+				viewer TEXT NOT NULL,
+				day    TEXT NOT NULL,
+				type   TEXT NOT NULL,
+				PRIMARY KEY (banner, viewer, day, type)
+			) WITHOUT ROWID
+		`).Execute()
+
+		return err
+	}, func(app core.App) error {
+		if _, err := app.DB().NewQuery("DROP TABLE IF EXISTS banner_events_seen").Execute(); err != nil {
+			return err
+		}
+
+		stats, err := app.FindCollectionByNameOrId("banner_stats")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(stats)
+	})
+}
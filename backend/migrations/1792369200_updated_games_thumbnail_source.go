@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// the games.image url the thumbnail file was mirrored from,
+		// the mirrorGameImages cron job downloads it again when they differ
+		games.Fields.Add(&core.TextField{Name: "thumbnail_source"})
+
+		return app.Save(games)
+	}, func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		games.Fields.RemoveByName("thumbnail_source")
+
+		return app.Save(games)
+	})
+}
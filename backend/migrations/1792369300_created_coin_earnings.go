@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// ledger of the coins credited to the users (spending isn't recorded),
+		// the time-windowed leaderboards are computed from it
+		earnings := core.NewBaseCollection("coin_earnings")
+		earnings.ListRule = types.Pointer("user = @request.auth.id")
+		earnings.ViewRule = types.Pointer("user = @request.auth.id")
+		earnings.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  "_pb_users_auth_",
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.NumberField{Name: "amount", OnlyInt: true, Required: true, Min: types.Pointer(1.0)},
+			&core.SelectField{
+				Name:      "source",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    []string{"opening_balance", "spin", "check_in", "play"},
+			},
+			// id of what the coins were earned with (prize, session...)
+			&core.TextField{Name: "ref"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		earnings.AddIndex("idx_coin_earnings_created_user", false, "created, user, amount", "")
+		earnings.AddIndex("idx_coin_earnings_user_created", false, "user, created", "")
+		if err := app.Save(earnings); err != nil {
+			return err
+		}
+
+		// the coins earned before the ledger existed count for the all-time
+		// leaderboard, dated at the signup so they don't count for the current periods
+		_, err := app.DB().NewQuery(`
+			INSERT INTO coin_earnings (id, user, amount, source, ref, created, updated)
+			SELECT
+				substr(lower(hex(randomblob(8))), 1, 15),
+				id, coins, 'opening_balance', '', created, created
+			FROM users
+			WHERE coins > 0
+		`).Execute()
+
+		return err
+	}, func(app core.App) error {
+		earnings, err := app.FindCollectionByNameOrId("coin_earnings")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(earnings)
+	})
+}
@@ -0,0 +1,119 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// 1. leaderboard_seasons (final standings of every ended daily/weekly/monthly period)
+		seasons := core.NewBaseCollection("leaderboard_seasons")
+		seasons.ListRule = types.Pointer("")
+		seasons.ViewRule = types.Pointer("")
+		seasons.Fields.Add(
+			&core.SelectField{
+				Name:      "period",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    []string{"daily", "weekly", "monthly"},
+			},
+			&core.DateField{Name: "starts_at", Required: true},
+			&core.DateField{Name: "ends_at", Required: true},
+			&core.NumberField{Name: "players", OnlyInt: true, Min: types.Pointer(0.0)},
+			// [{rank, user, username, avatar, score, reward}] of the top players
+			&core.JSONField{Name: "standings"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		seasons.AddIndex("idx_leaderboard_seasons_period_starts_at", true, "period, starts_at", "")
+		if err := app.Save(seasons); err != nil {
+			return err
+		}
+
+		// 2. leaderboard_rewards_config (coins per period and rank bracket, edited from the Dashboard)
+		config := core.NewBaseCollection("leaderboard_rewards_config")
+		config.ListRule = types.Pointer("")
+		config.ViewRule = types.Pointer("")
+		config.Fields.Add(
+			&core.SelectField{
+				Name:      "period",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    []string{"daily", "weekly", "monthly"},
+			},
+			&core.SelectField{
+				Name:      "bracket",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    []string{"1", "2-3", "4-10", "top_1_percent"},
+			},
+			&core.NumberField{Name: "coins", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		config.AddIndex("idx_leaderboard_rewards_config_period_bracket", true, "period, bracket", "")
+		if err := app.Save(config); err != nil {
+			return err
+		}
+
+		// 3. notifications (in-app inbox, eg. the season rewards)
+		notifications := core.NewBaseCollection("notifications")
+		notifications.ListRule = types.Pointer("user = @request.auth.id")
+		notifications.ViewRule = types.Pointer("user = @request.auth.id")
+		// only marking as read
+		notifications.UpdateRule = types.Pointer("user = @request.auth.id && " +
+			"@request.body.user:isset = false && @request.body.type:isset = false && @request.body.data:isset = false")
+		notifications.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  "_pb_users_auth_",
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.TextField{Name: "type", Required: true},
+			&core.JSONField{Name: "data"},
+			&core.DateField{Name: "read_at"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		notifications.AddIndex("idx_notifications_user_created", false, "user, created", "")
+		if err := app.Save(notifications); err != nil {
+			return err
+		}
+
+		// 4. the season rewards are credited through the coin_earnings ledger
+		earnings, err := app.FindCollectionByNameOrId("coin_earnings")
+		if err != nil {
+			return err
+		}
+		source := earnings.Fields.GetByName("source").(*core.SelectField)
+		source.Values = append(source.Values, "season_reward")
+
+		return app.Save(earnings)
+	}, func(app core.App) error {
+		earnings, err := app.FindCollectionByNameOrId("coin_earnings")
+		if err != nil {
+			return err
+		}
+		source := earnings.Fields.GetByName("source").(*core.SelectField)
+		source.Values = []string{"opening_balance", "spin", "check_in", "play"}
+		if err := app.Save(earnings); err != nil {
+			return err
+		}
+
+		for _, name := range []string{"leaderboard_seasons", "leaderboard_rewards_config", "notifications"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+			if err := app.Delete(collection); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// materialized leaderboards: the coins earned per user and period,
+		// kept up to date with every coin_earnings entry (see leaderboard.go)
+		_, err := app.DB().NewQuery(`
+			CREATE TABLE IF NOT EXISTS leaderboard_scores (
+				period       TEXT NOT NULL,
+				period_start TEXT NOT NULL,
+				user         TEXT NOT NULL,
+				score        INTEGER NOT NULL DEFAULT 0,
+				reached_at   TEXT NOT NULL,
+				PRIMARY KEY (period, period_start, user)
+			) WITHOUT ROWID
+		`).Execute()
+		if err != nil {
+			return err
+		}
+
+		// the ranking order: higher score first, earlier achiever first on ties
+		_, err = app.DB().NewQuery(`
+			CREATE INDEX IF NOT EXISTS idx_leaderboard_scores_rank
+			ON leaderboard_scores (period, period_start, score DESC, reached_at ASC, user ASC)
+		`).Execute()
+		if err != nil {
+			return err
+		}
+
+		// backfill from the ledger (the periods start on UTC days, Mondays and 1st of months)
+		_, err = app.DB().NewQuery(`
+			INSERT INTO leaderboard_scores (period, period_start, user, score, reached_at)
+			SELECT 'all_time', '', user, SUM(amount), MAX(created)
+			FROM coin_earnings
+			WHERE source != 'season_reward'
+			GROUP BY user
+			UNION ALL
+			SELECT 'daily', date(created) || ' 00:00:00.000Z', user, SUM(amount), MAX(created)
+			FROM coin_earnings
+			WHERE source NOT IN ('season_reward', 'opening_balance')
+			GROUP BY 2, user
+			UNION ALL
+			SELECT 'weekly', date(created, '-6 days', 'weekday 1') || ' 00:00:00.000Z', user, SUM(amount), MAX(created)
+			FROM coin_earnings
+			WHERE source NOT IN ('season_reward', 'opening_balance')
+			GROUP BY 2, user
+			UNION ALL
+			SELECT 'monthly', strftime('%Y-%m-01', created) || ' 00:00:00.000Z', user, SUM(amount), MAX(created)
+			FROM coin_earnings
+			WHERE source NOT IN ('season_reward', 'opening_balance')
+			GROUP BY 2, user
+		`).Execute()
+
+		return err
+	}, func(app core.App) error {
+		_, err := app.DB().NewQuery("DROP TABLE IF EXISTS leaderboard_scores").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// the user whose referral code was used at signup (set by the users
+		// create hook from the `referrer_code` body param, see friends.go)
+		users.Fields.Add(&core.RelationField{
+			Name:         "referred_by",
+			CollectionId: users.Id,
+			MaxSelect:    1,
+			Hidden:       true,
+		})
+		if err := app.Save(users); err != nil {
+			return err
+		}
+
+		// one record per pair of users, whichever sent the request;
+		// created and accepted through the /api/friends routes
+		friendships := core.NewBaseCollection("friendships")
+		friendships.ListRule = types.Pointer("requester = @request.auth.id || addressee = @request.auth.id")
+		friendships.ViewRule = types.Pointer("requester = @request.auth.id || addressee = @request.auth.id")
+		friendships.Fields.Add(
+			&core.RelationField{
+				Name:          "requester",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.RelationField{
+				Name:          "addressee",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.SelectField{
+				Name:      "status",
+				Values:    []string{"pending", "accepted"},
+				MaxSelect: 1,
+				Required:  true,
+			},
+			&core.SelectField{
+				Name:      "source",
+				Values:    []string{"request", "referral"},
+				MaxSelect: 1,
+			},
+			&core.DateField{Name: "accepted_at"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		friendships.AddIndex("idx_friendships_requester_addressee", true, "requester, addressee", "")
+		friendships.AddIndex("idx_friendships_addressee_status", false, "addressee, status", "")
+
+		return app.Save(friendships)
+	}, func(app core.App) error {
+		friendships, err := app.FindCollectionByNameOrId("friendships")
+		if err != nil {
+			return err
+		}
+		if err := app.Delete(friendships); err != nil {
+			return err
+		}
+
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		users.Fields.RemoveByName("referred_by")
+
+		return app.Save(users)
+	})
+}
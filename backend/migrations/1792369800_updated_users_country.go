@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// ISO 3166-1 alpha-2 code, detected from the signup IP (see
+		// countries.go) and editable in the profile
+		users.Fields.Add(&core.TextField{Name: "country", Max: 2, Pattern: "^[A-Z]{2}$"})
+		users.AddIndex("idx_users_country", false, "country", "")
+
+		return app.Save(users)
+	}, func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		users.RemoveIndex("idx_users_country")
+		users.Fields.RemoveByName("country")
+
+		return app.Save(users)
+	})
+}
@@ -0,0 +1,180 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		xpSources := []string{"spin", "check_in", "play", "achievement", "referral"}
+		// the levels the curve is seeded with, the Dashboard can extend it
+		seededMaxLevel := 30
+
+		// 1. users: total XP and the extra daily spins of the level-ups
+		// (hidden, so only the server changes them)
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		users.Fields.Add(
+			&core.NumberField{Name: "xp", OnlyInt: true, Min: types.Pointer(0.0), Hidden: true},
+			&core.NumberField{Name: "bonus_spins", OnlyInt: true, Min: types.Pointer(0.0), Hidden: true},
+		)
+		if err := app.Save(users); err != nil {
+			return err
+		}
+
+		// 2. xp_events (ledger of the XP granted to the users)
+		events := core.NewBaseCollection("xp_events")
+		events.ListRule = types.Pointer("user = @request.auth.id")
+		events.ViewRule = types.Pointer("user = @request.auth.id")
+		events.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.NumberField{Name: "amount", OnlyInt: true, Required: true, Min: types.Pointer(1.0)},
+			&core.SelectField{
+				Name:      "source",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    xpSources,
+			},
+			// id of what the XP was granted for (prize, session, referred user...)
+			&core.TextField{Name: "ref"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		events.AddIndex("idx_xp_events_user_created", false, "user, created", "")
+		if err := app.Save(events); err != nil {
+			return err
+		}
+
+		// 3. xp_rewards_config (XP per source, edited from the Dashboard;
+		// "play" is per verified minute)
+		config := core.NewBaseCollection("xp_rewards_config")
+		config.ListRule = types.Pointer("")
+		config.ViewRule = types.Pointer("")
+		config.Fields.Add(
+			&core.SelectField{
+				Name:      "source",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    xpSources,
+			},
+			&core.NumberField{Name: "xp", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		config.AddIndex("idx_xp_rewards_config_source", true, "source", "")
+		if err := app.Save(config); err != nil {
+			return err
+		}
+
+		// 4. level_curve (total XP each level is reached at and its rewards)
+		curve := core.NewBaseCollection("level_curve")
+		curve.ListRule = types.Pointer("")
+		curve.ViewRule = types.Pointer("")
+		curve.Fields.Add(
+			&core.NumberField{Name: "level", OnlyInt: true, Required: true, Min: types.Pointer(2.0)},
+			&core.NumberField{Name: "xp_required", OnlyInt: true, Required: true, Min: types.Pointer(1.0)},
+			&core.NumberField{Name: "reward_coins", OnlyInt: true, Min: types.Pointer(0.0)},
+			// added to the daily lucky wheel spins for good
+			&core.NumberField{Name: "reward_spins", OnlyInt: true, Min: types.Pointer(0.0)},
+			// keys of the features the level unlocks
+			&core.JSONField{Name: "unlocks"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		curve.AddIndex("idx_level_curve_level", true, "level", "")
+		if err := app.Save(curve); err != nil {
+			return err
+		}
+
+		// 50 * (level-1) * level: 100 XP for level 2, 300 for 3, 4500 for 10...
+		// an extra daily spin every 5 levels
+		for level := 2; level <= seededMaxLevel; level++ {
+			record := core.NewRecord(curve)
+			record.Set("level", level)
+			record.Set("xp_required", 50*(level-1)*level)
+			record.Set("reward_coins", 25*level)
+			if level%5 == 0 {
+				record.Set("reward_spins", 1)
+			}
+			record.Set("unlocks", []string{})
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		// 5. level_ups (one per user and level reached, with the rewards given)
+		levelUps := core.NewBaseCollection("level_ups")
+		levelUps.ListRule = types.Pointer("user = @request.auth.id")
+		levelUps.ViewRule = types.Pointer("user = @request.auth.id")
+		levelUps.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.NumberField{Name: "level", OnlyInt: true, Required: true},
+			&core.NumberField{Name: "reward_coins", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.NumberField{Name: "reward_spins", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.JSONField{Name: "unlocks"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		levelUps.AddIndex("idx_level_ups_user_level", true, "user, level", "")
+		if err := app.Save(levelUps); err != nil {
+			return err
+		}
+
+		// 6. the level-up coins go through the coins ledger
+		earnings, err := app.FindCollectionByNameOrId("coin_earnings")
+		if err != nil {
+			return err
+		}
+		source := earnings.Fields.GetByName("source").(*core.SelectField)
+		source.Values = append(source.Values, "level_up")
+
+		return app.Save(earnings)
+	}, func(app core.App) error {
+		earnings, err := app.FindCollectionByNameOrId("coin_earnings")
+		if err != nil {
+			return err
+		}
+		source := earnings.Fields.GetByName("source").(*core.SelectField)
+		source.Values = slices.DeleteFunc(source.Values, func(v string) bool { return v == "level_up" })
+		if err := app.Save(earnings); err != nil {
+			return err
+		}
+
+		for _, name := range []string{"level_ups", "level_curve", "xp_rewards_config", "xp_events"} {
+			collection, err := app.FindCollectionByNameOrId(name)
+			if err != nil {
+				return err
+			}
+			if err := app.Delete(collection); err != nil {
+				return err
+			}
+		}
+
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		users.Fields.RemoveByName("xp")
+		users.Fields.RemoveByName("bonus_spins")
+
+		return app.Save(users)
+	})
+}
@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// the public profile sections, shown unless hidden
+		// (the favorite games only once shared)
+		users.Fields.Add(
+			&core.BoolField{Name: "hide_level"},
+			&core.BoolField{Name: "hide_achievements"},
+			&core.BoolField{Name: "hide_join_date"},
+			&core.BoolField{Name: "share_favorites"},
+		)
+		if err := app.Save(users); err != nil {
+			return err
+		}
+
+		// the per user achievement progress the app reads (the collection
+		// only had the referral fields)
+		achievements, err := app.FindCollectionByNameOrId("achievements")
+		if err != nil {
+			return err
+		}
+
+		userAchievements, err := app.FindCollectionByNameOrId("user_achievements")
+		if err != nil {
+			return err
+		}
+		userAchievements.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+			},
+			&core.RelationField{
+				Name:          "achievement",
+				CollectionId:  achievements.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+			},
+			&core.NumberField{Name: "current_value", OnlyInt: true, Min: types.Pointer(0.0)},
+			&core.BoolField{Name: "is_claimed"},
+		)
+		userAchievements.AddIndex("idx_user_achievements_user_achievement", false, "user, achievement", "")
+
+		return app.Save(userAchievements)
+	}, func(app core.App) error {
+		userAchievements, err := app.FindCollectionByNameOrId("user_achievements")
+		if err != nil {
+			return err
+		}
+		userAchievements.RemoveIndex("idx_user_achievements_user_achievement")
+		userAchievements.Fields.RemoveByName("user")
+		userAchievements.Fields.RemoveByName("achievement")
+		userAchievements.Fields.RemoveByName("current_value")
+		userAchievements.Fields.RemoveByName("is_claimed")
+		if err := app.Save(userAchievements); err != nil {
+			return err
+		}
+
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		users.Fields.RemoveByName("hide_level")
+		users.Fields.RemoveByName("hide_achievements")
+		users.Fields.RemoveByName("hide_join_date")
+		users.Fields.RemoveByName("share_favorites")
+
+		return app.Save(users)
+	})
+}
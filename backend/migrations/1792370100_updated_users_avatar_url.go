@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+const dicebearAvatarPrefix = "https://api.dicebear.com/"
+
+// the avatars are generated by the app itself (/api/avatars/{seed}) instead of
+// dicebear, the existing dicebear urls keep their seed
+func init() {
+	m.Register(func(app core.App) error {
+		appURL := strings.TrimRight(app.Settings().Meta.AppURL, "/")
+
+		return replaceAvatarURLs(app, dicebearAvatarPrefix+"%", func(avatarURL string) string {
+			u, err := url.Parse(avatarURL)
+			if err != nil || u.Query().Get("seed") == "" {
+				return avatarURL
+			}
+			return appURL + "/api/avatars/" + url.PathEscape(u.Query().Get("seed")) + ".png"
+		})
+	}, func(app core.App) error {
+		prefix := strings.TrimRight(app.Settings().Meta.AppURL, "/") + "/api/avatars/"
+
+		return replaceAvatarURLs(app, prefix+"%", func(avatarURL string) string {
+			seed, err := url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(avatarURL, prefix), ".png"))
+			if err != nil {
+				return avatarURL
+			}
+			return dicebearAvatarPrefix + "9.x/avataaars/png?seed=" + url.QueryEscape(seed)
+		})
+	})
+}
+
+// replaceAvatarURLs rewrites the users.avatar_url values matching the LIKE pattern.
+func replaceAvatarURLs(app core.App, pattern string, replace func(string) string) error {
+	var rows []struct {
+		Id        string `db:"id"`
+		AvatarURL string `db:"avatar_url"`
+	}
+	err := app.DB().
+		Select("id", "avatar_url").
+		From("users").
+		Where(dbx.NewExp("avatar_url LIKE {:pattern}", dbx.Params{"pattern": pattern})).
+		All(&rows)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		avatarURL := replace(row.AvatarURL)
+		if avatarURL == row.AvatarURL {
+			continue
+		}
+
+		_, err := app.DB().
+			Update("users", dbx.Params{"avatar_url": avatarURL}, dbx.HashExp{"id": row.Id}).
+			Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// the level_curve unlock that skips the avatar moderation queue, and the
+// level it is seeded at
+const (
+	avatarAutoApproveUnlock = "avatar_auto_approve"
+	avatarAutoApproveLevel  = 5
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// 1. avatar_uploads (the moderation queue, the images are the
+		// processed square sizes, never the uploaded file)
+		uploads := core.NewBaseCollection("avatar_uploads")
+		uploads.ListRule = types.Pointer("user = @request.auth.id")
+		uploads.ViewRule = types.Pointer("user = @request.auth.id")
+		uploads.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				CascadeDelete: true,
+				MaxSelect:     1,
+				Required:      true,
+			},
+			&core.FileField{
+				Name:      "images",
+				MaxSelect: 4,
+				MaxSize:   2 << 20,
+				MimeTypes: []string{"image/jpeg"},
+				Required:  true,
+			},
+			&core.SelectField{
+				Name:      "status",
+				MaxSelect: 1,
+				Required:  true,
+				// replaced: a newer upload came before the review
+				Values: []string{"pending", "approved", "rejected", "reverted", "replaced"},
+			},
+			// the users.avatar_url the approval replaced, restored by a revert
+			&core.TextField{Name: "previous_avatar_url"},
+			&core.TextField{Name: "reject_reason", Max: 500},
+			&core.DateField{Name: "reviewed_at"},
+			&core.AutodateField{Name: "created", OnCreate: true},
+			&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+		)
+		uploads.AddIndex("idx_avatar_uploads_status_created", false, "status, created", "")
+		uploads.AddIndex("idx_avatar_uploads_user", false, "user", "")
+		if err := app.Save(uploads); err != nil {
+			return err
+		}
+
+		// 2. the trusted levels skip the queue
+		step, err := app.FindFirstRecordByData("level_curve", "level", avatarAutoApproveLevel)
+		if err != nil {
+			return err
+		}
+		var unlocks []string
+		_ = step.UnmarshalJSONField("unlocks", &unlocks)
+		if !slices.Contains(unlocks, avatarAutoApproveUnlock) {
+			step.Set("unlocks", append(unlocks, avatarAutoApproveUnlock))
+		}
+
+		return app.Save(step)
+	}, func(app core.App) error {
+		step, err := app.FindFirstRecordByData("level_curve", "level", avatarAutoApproveLevel)
+		if err == nil {
+			var unlocks []string
+			_ = step.UnmarshalJSONField("unlocks", &unlocks)
+			step.Set("unlocks", slices.DeleteFunc(unlocks, func(v string) bool { return v == avatarAutoApproveUnlock }))
+			if err := app.Save(step); err != nil {
+				return err
+			}
+		}
+
+		uploads, err := app.FindCollectionByNameOrId("avatar_uploads")
+		if err != nil {
+			return err
+		}
+
+		return app.Delete(uploads)
+	})
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// a pair of users has one friendship whichever sent the request, the
+// requester/addressee index alone allowed a second one the other way around
+func init() {
+	m.Register(func(app core.App) error {
+		// keep the accepted (then the oldest) one of the existing duplicates
+		_, err := app.DB().NewQuery(`
+			DELETE FROM friendships WHERE id IN (
+				SELECT id FROM (
+					SELECT id, ROW_NUMBER() OVER (
+						PARTITION BY MIN(requester, addressee), MAX(requester, addressee)
+						ORDER BY status = 'accepted' DESC, created ASC
+					) AS n
+					FROM friendships
+				)
+				WHERE n > 1
+			)
+		`).Execute()
+		if err != nil {
+			return err
+		}
+
+		friendships, err := app.FindCollectionByNameOrId("friendships")
+		if err != nil {
+			return err
+		}
+
+		friendships.AddIndex("idx_friendships_pair", true, "MIN(requester, addressee), MAX(requester, addressee)", "")
+
+		return app.Save(friendships)
+	}, func(app core.App) error {
+		friendships, err := app.FindCollectionByNameOrId("friendships")
+		if err != nil {
+			return err
+		}
+
+		friendships.RemoveIndex("idx_friendships_pair")
+
+		return app.Save(friendships)
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// the achievement progress (and the referral rows) are only readable by
+// their user, the public profile shows the completed achievements unless
+// hidden (see profiles.go)
+func init() {
+	m.Register(func(app core.App) error {
+		userAchievements, err := app.FindCollectionByNameOrId("user_achievements")
+		if err != nil {
+			return err
+		}
+
+		userAchievements.ListRule = types.Pointer("user = @request.auth.id")
+		userAchievements.ViewRule = types.Pointer("user = @request.auth.id")
+
+		return app.Save(userAchievements)
+	}, func(app core.App) error {
+		userAchievements, err := app.FindCollectionByNameOrId("user_achievements")
+		if err != nil {
+			return err
+		}
+
+		userAchievements.ListRule = types.Pointer("")
+		userAchievements.ViewRule = types.Pointer("")
+
+		return app.Save(userAchievements)
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// relative to the API url (see avatars.go)
+const generatedAvatarPrefix = "/api/avatars/"
+
+// the generated avatar urls were stored with the application URL of the
+// settings, they are relative to the API url now (whatever host the app is
+// served from)
+func init() {
+	m.Register(func(app core.App) error {
+		return replaceAvatarURLs(app, "http%"+generatedAvatarPrefix+"%", func(avatarURL string) string {
+			u, err := url.Parse(avatarURL)
+			if err != nil || !strings.HasPrefix(u.Path, generatedAvatarPrefix) {
+				return avatarURL
+			}
+			return u.EscapedPath()
+		})
+	}, func(app core.App) error {
+		appURL := strings.TrimRight(app.Settings().Meta.AppURL, "/")
+
+		return replaceAvatarURLs(app, generatedAvatarPrefix+"%", func(avatarURL string) string {
+			return appURL + avatarURL
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// the approved uploads were stored with the application URL of the settings
+// (users.avatar_url and the avatar_uploads.previous_avatar_url restored by a
+// revert), they are relative to the API url now like the generated avatars
+func init() {
+	m.Register(func(app core.App) error {
+		uploads, err := app.FindCollectionByNameOrId("avatar_uploads")
+		if err != nil {
+			return err
+		}
+		uploadsPrefix := "/api/files/" + uploads.Id + "/"
+
+		relative := func(avatarURL string) string {
+			u, err := url.Parse(avatarURL)
+			if err != nil || !(strings.HasPrefix(u.Path, uploadsPrefix) || strings.HasPrefix(u.Path, generatedAvatarPrefix)) {
+				return avatarURL
+			}
+			return u.EscapedPath()
+		}
+
+		if err := replaceAvatarURLs(app, "http%"+uploadsPrefix+"%", relative); err != nil {
+			return err
+		}
+
+		return replaceURLs(app, "avatar_uploads", "previous_avatar_url", "http%/api/%", relative)
+	}, func(app core.App) error {
+		uploads, err := app.FindCollectionByNameOrId("avatar_uploads")
+		if err != nil {
+			return err
+		}
+		uploadsPrefix := "/api/files/" + uploads.Id + "/"
+
+		appURL := strings.TrimRight(app.Settings().Meta.AppURL, "/")
+		absolute := func(avatarURL string) string {
+			return appURL + avatarURL
+		}
+
+		if err := replaceAvatarURLs(app, uploadsPrefix+"%", absolute); err != nil {
+			return err
+		}
+
+		return replaceURLs(app, "avatar_uploads", "previous_avatar_url", "/api/%", absolute)
+	})
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		// the publisher feed the game was imported from (famobi,
+		// gamedistribution...), the external ids are only unique within it.
+		// The games imported before it get theirs the next time their feed
+		// is imported.
+		games.Fields.Add(&core.TextField{Name: "source"})
+		games.RemoveIndex("idx_games_external_id")
+		games.AddIndex("idx_games_source_external_id", true, "source, external_id", "external_id != ''")
+
+		return app.Save(games)
+	}, func(app core.App) error {
+		games, err := app.FindCollectionByNameOrId("games")
+		if err != nil {
+			return err
+		}
+
+		games.RemoveIndex("idx_games_source_external_id")
+		games.AddIndex("idx_games_external_id", true, "external_id", "external_id != ''")
+		games.Fields.RemoveByName("source")
+
+		return app.Save(games)
+	})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// nothing tracks the achievement progress yet, so the achievements don't
+// grant XP and can't be configured as an XP source
+func init() {
+	m.Register(func(app core.App) error {
+		_, err := app.DB().Delete("xp_rewards_config", dbx.HashExp{"source": "achievement"}).Execute()
+		if err != nil {
+			return err
+		}
+
+		config, err := app.FindCollectionByNameOrId("xp_rewards_config")
+		if err != nil {
+			return err
+		}
+
+		source := config.Fields.GetByName("source").(*core.SelectField)
+		source.Values = slices.DeleteFunc(source.Values, func(v string) bool { return v == "achievement" })
+
+		return app.Save(config)
+	}, func(app core.App) error {
+		config, err := app.FindCollectionByNameOrId("xp_rewards_config")
+		if err != nil {
+			return err
+		}
+
+		source := config.Fields.GetByName("source").(*core.SelectField)
+		source.Values = append(source.Values, "achievement")
+
+		return app.Save(config)
+	})
+}
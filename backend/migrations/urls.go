@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// replaceURLs rewrites the values of a table's text column matching the
+// LIKE pattern.
+func replaceURLs(app core.App, table string, column string, pattern string, replace func(string) string) error {
+	var rows []struct {
+		Id    string `db:"id"`
+		Value string `db:"value"`
+	}
+	err := app.DB().
+		Select("id", column+" AS value").
+		From(table).
+		Where(dbx.NewExp(app.DB().QuoteSimpleColumnName(column)+" LIKE {:pattern}", dbx.Params{"pattern": pattern})).
+		All(&rows)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		value := replace(row.Value)
+		if value == row.Value {
+			continue
+		}
+
+		_, err := app.DB().
+			Update(table, dbx.Params{column: value}, dbx.HashExp{"id": row.Id}).
+			Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// completed achievements shown on a public profile
+	profileAchievementsLimit = 6
+	// shared favorite games shown on a public profile
+	profileFavoritesLimit = 10
+)
+
+func registerProfileRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Public Profile of a Player
+	// Only what the users table is safe to show (never the email, coins or
+	// referral internals); the sections the player hid are null.
+	e.Router.GET("/api/users/{id}/profile", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		user, err := app.FindRecordById("users", re.Request.PathValue("id"))
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "user_not_found", err)
+		}
+
+		lang := requestLang(re)
+
+		profile := map[string]any{
+			"id":             user.Id,
+			"username":       user.GetString("username"),
+			"name":           nil,
+			"avatar_url":     user.GetString("avatar_url"),
+			"level":          nil,
+			"joined":         nil,
+			"achievements":   nil,
+			"favorite_games": nil,
+		}
+
+		if !user.GetBool("hide_name") {
+			profile["name"] = user.GetString("name")
+		}
+		if !user.GetBool("hide_level") {
+			profile["level"] = user.GetInt("level")
+		}
+		if !user.GetBool("hide_join_date") {
+			profile["joined"] = user.GetDateTime("created").String()
+		}
+
+		if !user.GetBool("hide_achievements") {
+			achievements, err := profileAchievements(app, user.Id, lang)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+			}
+			profile["achievements"] = achievements
+		}
+
+		if user.GetBool("share_favorites") {
+			favorites, err := app.FindRecordsByFilter(
+				"user_favorites",
+				"user = {:user} && game.is_active = true",
+				"-created",
+				profileFavoritesLimit, 0,
+				dbx.Params{"user": user.Id},
+			)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+			}
+			if errs := app.ExpandRecords(favorites, []string{"game", "game.category"}, nil); len(errs) > 0 {
+				log.Println("Profile favorites expand errors:", errs)
+			}
+
+			games := make([]*core.Record, 0, len(favorites))
+			for _, f := range favorites {
+				if game := f.ExpandedOne("game"); game != nil {
+					games = append(games, game)
+				}
+			}
+			localizeRecords(games, lang)
+			profile["favorite_games"] = games
+		}
+
+		if user.Id != authRecord.Id {
+			profile["friendship"] = friendshipStatus(app, authRecord.Id, user.Id)
+		}
+
+		return re.JSON(http.StatusOK, profile)
+	})
+}
+
+// profileAchievements returns the achievements the user completed, the
+// latest first.
+func profileAchievements(app core.App, userId string, lang string) ([]map[string]any, error) {
+	progress, err := app.FindRecordsByFilter(
+		"user_achievements",
+		"user = {:user} && achievement != '' && current_value >= achievement.target_value",
+		"-updated",
+		profileAchievementsLimit, 0,
+		dbx.Params{"user": userId},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if errs := app.ExpandRecords(progress, []string{"achievement"}, nil); len(errs) > 0 {
+		log.Println("Profile achievements expand errors:", errs)
+	}
+
+	items := make([]map[string]any, 0, len(progress))
+	for _, p := range progress {
+		achievement := p.ExpandedOne("achievement")
+		if achievement == nil {
+			continue
+		}
+		localizeRecords([]*core.Record{achievement}, lang)
+
+		items = append(items, map[string]any{
+			"id":           achievement.Id,
+			"title":        achievement.GetString("title"),
+			"description":  achievement.GetString("description"),
+			"icon":         achievement.GetString("icon"),
+			"completed_at": p.GetDateTime("updated").String(),
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// approved reviews with that many reports are hidden again until a moderator looks at them
+	reviewReportThreshold = 3
+	// approved reviews returned by the game details route
+	gameDetailsReviewsLimit = 10
+)
+
+// profanityWords are masked in the reviews before they are stored.
+// Kept short on purpose, the moderators catch the rest.
+var profanityWords = []string{
+	"fuck", "fucking", "shit", "bitch", "bastard", "asshole", "dick", "cunt",
+	"whore", "slut", "fag", "nigger", "retard", "motherfucker", "bullshit",
+	"scheisse", "scheiße", "arschloch", "puta", "mierda", "cabron", "putain", "merde", "connard",
+}
+
+var profanityRegex = regexp.MustCompile(`(?i)\b(` + strings.Join(quoteAll(profanityWords), "|") + `)\b`)
+
+func quoteAll(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return quoted
+}
+
+// filterProfanity masks the profane words of a review, eg. "shit game" -> "s*** game".
+func filterProfanity(text string) string {
+	return profanityRegex.ReplaceAllStringFunc(text, func(word string) string {
+		runes := []rune(word)
+		return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	})
+}
+
+type ratingSubmission struct {
+	Rating int    `json:"rating"`
+	Review string `json:"review"`
+}
+
+func registerRatingRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Rate a Game (creates or replaces the user's rating)
+	e.Router.POST("/api/games/{id}/rating", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		var body ratingSubmission
+		if err := re.BindBody(&body); err != nil {
+			return apiError(re, http.StatusBadRequest, "invalid_request", err)
+		}
+		if body.Rating < 1 || body.Rating > 5 {
+			return apiError(re, http.StatusBadRequest, "rating_out_of_range", nil)
+		}
+
+		game, err := app.FindRecordById("games", re.Request.PathValue("id"))
+		if err != nil || !game.GetBool("is_active") {
+			return apiError(re, http.StatusNotFound, "game_not_found", err)
+		}
+
+		rating, err := app.FindFirstRecordByFilter(
+			"game_ratings",
+			"user = {:user} && game = {:game}",
+			map[string]any{"user": authRecord.Id, "game": game.Id},
+		)
+		if err != nil {
+			ratingsCollection, err := app.FindCollectionByNameOrId("game_ratings")
+			if err != nil {
+				return err
+			}
+			rating = core.NewRecord(ratingsCollection)
+			rating.Set("user", authRecord.Id)
+			rating.Set("game", game.Id)
+		}
+
+		review := filterProfanity(strings.TrimSpace(body.Review))
+
+		// every new or edited review goes (back) through moderation
+		if review != rating.GetString("review") {
+			rating.Set("review", review)
+			rating.Set("report_count", 0)
+			if review == "" {
+				rating.Set("review_status", "")
+			} else {
+				rating.Set("review_status", "pending")
+			}
+		}
+		rating.Set("rating", body.Rating)
+
+		if err := app.Save(rating); err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		// reload for the aggregates updated by the hooks
+		game, err = app.FindRecordById("games", game.Id)
+		if err != nil {
+			return err
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success":      true,
+			"user_rating":  userRatingPayload(rating),
+			"rating_avg":   game.GetFloat("rating_avg"),
+			"rating_count": game.GetInt("rating_count"),
+		})
+	})
+
+	// ROUTE: Remove own Rating
+	e.Router.DELETE("/api/games/{id}/rating", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		rating, err := app.FindFirstRecordByFilter(
+			"game_ratings",
+			"user = {:user} && game = {:game}",
+			map[string]any{"user": authRecord.Id, "game": re.Request.PathValue("id")},
+		)
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "rating_not_found", err)
+		}
+
+		if err := app.Delete(rating); err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+		})
+	})
+
+	// ROUTE: Report a Review
+	e.Router.POST("/api/ratings/{id}/report", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		_ = re.BindBody(&body)
+
+		rating, err := app.FindRecordById("game_ratings", re.Request.PathValue("id"))
+		if err != nil || rating.GetString("review_status") != "approved" {
+			return apiError(re, http.StatusNotFound, "review_not_found", err)
+		}
+		if rating.GetString("user") == authRecord.Id {
+			return apiError(re, http.StatusBadRequest, "cannot_report_own_review", nil)
+		}
+
+		existing, _ := app.FindFirstRecordByFilter(
+			"game_review_reports",
+			"user = {:user} && rating = {:rating}",
+			map[string]any{"user": authRecord.Id, "rating": rating.Id},
+		)
+		if existing != nil {
+			return apiError(re, http.StatusBadRequest, "review_already_reported", nil)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			reportsCollection, err := txApp.FindCollectionByNameOrId("game_review_reports")
+			if err != nil {
+				return err
+			}
+
+			report := core.NewRecord(reportsCollection)
+			report.Set("user", authRecord.Id)
+			report.Set("rating", rating.Id)
+			report.Set("reason", strings.TrimSpace(body.Reason))
+			if err := txApp.Save(report); err != nil {
+				return err
+			}
+
+			rating.Set("report_count+", 1)
+			if rating.GetInt("report_count") >= reviewReportThreshold {
+				rating.Set("review_status", "pending")
+			}
+			return txApp.Save(rating)
+		})
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+		})
+	})
+
+	// ROUTE: Game Details (game + rating aggregates + own rating + approved reviews)
+	e.Router.GET("/api/games/{id}/details", func(re *core.RequestEvent) error {
+		game, err := app.FindRecordById("games", re.Request.PathValue("id"))
+		if err != nil || !game.GetBool("is_active") {
+			return apiError(re, http.StatusNotFound, "game_not_found", err)
+		}
+
+		if errs := app.ExpandRecord(game, []string{"category", "tags"}, nil); len(errs) > 0 {
+			log.Println("Game details expand errors:", errs)
+		}
+		localizeRecords([]*core.Record{game}, requestLang(re))
+
+		// stars distribution, "1".."5" -> count
+		var buckets []struct {
+			Rating int `db:"rating"`
+			Total  int `db:"total"`
+		}
+		err = app.DB().NewQuery(`
+			SELECT rating, COUNT(*) AS total
+			FROM game_ratings
+			WHERE game = {:game}
+			GROUP BY rating
+		`).Bind(dbx.Params{"game": game.Id}).All(&buckets)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		distribution := map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+		for _, b := range buckets {
+			distribution[b.Rating] = b.Total
+		}
+
+		reviews, err := app.FindRecordsByFilter(
+			"game_ratings",
+			"game = {:game} && review_status = 'approved'",
+			"-updated",
+			gameDetailsReviewsLimit, 0,
+			map[string]any{"game": game.Id},
+		)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		if errs := app.ExpandRecords(reviews, []string{"user"}, nil); len(errs) > 0 {
+			log.Println("Game details expand errors:", errs)
+		}
+
+		type ReviewItem struct {
+			ID        string `json:"id"`
+			Username  string `json:"username"`
+			Avatar    string `json:"avatar"`
+			Rating    int    `json:"rating"`
+			Review    string `json:"review"`
+			Updated   string `json:"updated"`
+			IsCurrent bool   `json:"is_current_user"`
+		}
+
+		var authId string
+		if re.Auth != nil {
+			authId = re.Auth.Id
+		}
+
+		reviewItems := make([]ReviewItem, 0, len(reviews))
+		for _, r := range reviews {
+			item := ReviewItem{
+				ID:        r.Id,
+				Rating:    r.GetInt("rating"),
+				Review:    r.GetString("review"),
+				Updated:   r.GetDateTime("updated").String(),
+				IsCurrent: r.GetString("user") == authId,
+			}
+			if user := r.ExpandedOne("user"); user != nil {
+				item.Username = user.GetString("username")
+				item.Avatar = user.GetString("avatar_url")
+			}
+			reviewItems = append(reviewItems, item)
+		}
+
+		var userRating map[string]any
+		if authId != "" {
+			own, err := app.FindFirstRecordByFilter(
+				"game_ratings",
+				"user = {:user} && game = {:game}",
+				map[string]any{"user": authId, "game": game.Id},
+			)
+			if err == nil {
+				userRating = userRatingPayload(own)
+			}
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"game":         game,
+			"rating_avg":   game.GetFloat("rating_avg"),
+			"rating_count": game.GetInt("rating_count"),
+			"distribution": distribution,
+			"user_rating":  userRating,
+			"reviews":      reviewItems,
+		})
+	})
+}
+
+func userRatingPayload(rating *core.Record) map[string]any {
+	return map[string]any{
+		"id":            rating.Id,
+		"rating":        rating.GetInt("rating"),
+		"review":        rating.GetString("review"),
+		"review_status": rating.GetString("review_status"),
+		"updated":       rating.GetDateTime("updated").String(),
+	}
+}
+
+func registerRatingHooks(app core.App) {
+	app.OnRecordAfterCreateSuccess("game_ratings").BindFunc(func(e *core.RecordEvent) error {
+		if err := updateGameRatingAggregates(e.App, e.Record.GetString("game")); err != nil {
+			log.Printf("ERROR updating the ratings of game %s: %v", e.Record.GetString("game"), err)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordAfterUpdateSuccess("game_ratings").BindFunc(func(e *core.RecordEvent) error {
+		if err := updateGameRatingAggregates(e.App, e.Record.GetString("game")); err != nil {
+			log.Printf("ERROR updating the ratings of game %s: %v", e.Record.GetString("game"), err)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordAfterDeleteSuccess("game_ratings").BindFunc(func(e *core.RecordEvent) error {
+		if err := updateGameRatingAggregates(e.App, e.Record.GetString("game")); err != nil {
+			log.Printf("ERROR updating the ratings of game %s: %v", e.Record.GetString("game"), err)
+		}
+		return e.Next()
+	})
+
+	// reviews edited from the dashboard go through the same filter
+	app.OnRecordCreateRequest("game_ratings").BindFunc(func(e *core.RecordRequestEvent) error {
+		e.Record.Set("review", filterProfanity(e.Record.GetString("review")))
+		return e.Next()
+	})
+	app.OnRecordUpdateRequest("game_ratings").BindFunc(func(e *core.RecordRequestEvent) error {
+		e.Record.Set("review", filterProfanity(e.Record.GetString("review")))
+		return e.Next()
+	})
+}
+
+// updateGameRatingAggregates recomputes rating_avg and rating_count of a game
+// from all of its ratings (the stars count even while the review is moderated).
+func updateGameRatingAggregates(app core.App, gameId string) error {
+	game, err := app.FindRecordById("games", gameId)
+	if err != nil {
+		// the game itself was deleted (cascade)
+		return nil
+	}
+
+	var stats struct {
+		Avg   float64 `db:"avg"`
+		Total int     `db:"total"`
+	}
+	err = app.DB().NewQuery(`
+		SELECT COALESCE(AVG(rating), 0) AS avg, COUNT(*) AS total
+		FROM game_ratings
+		WHERE game = {:game}
+	`).Bind(dbx.Params{"game": gameId}).One(&stats)
+	if err != nil {
+		return err
+	}
+
+	game.Set("rating_avg", math.Round(stats.Avg*100)/100)
+	game.Set("rating_count", stats.Total)
+
+	return app.Save(game)
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"cmp"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	recommendDefaultLimit = 10
+	recommendMaxLimit     = 30
+
+	// play history taken into account, for the user and for the co-occurrence
+	recommendHistoryWindow = 90 * 24 * time.Hour
+
+	// score = co-occurrence + category affinity + freshness, each normalized to 0-1
+	recommendCoOccurrenceWeight = 0.5
+	recommendAffinityWeight     = 0.35
+	recommendFreshnessWeight    = 0.15
+	// the freshness halves about every 3 weeks
+	recommendFreshnessDays = 30.0
+	// tags count half as much as the main category
+	recommendTagWeight = 0.5
+
+	// co-occurrence job: pairs with fewer common players are noise
+	similarityMinCoPlayers = 2
+	// most similar games kept per game
+	similarityTopK = 20
+)
+
+func registerRecommendationRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Personalized Game Recommendations (?limit=)
+	e.Router.GET("/api/me/recommended-games", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		limit, _ := strconv.Atoi(re.Request.URL.Query().Get("limit"))
+		if limit <= 0 {
+			limit = recommendDefaultLimit
+		}
+		limit = min(limit, recommendMaxLimit)
+
+		ids, err := recommendedGameIds(app, authRecord.Id, limit)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		// cold start (or not enough signal), fill up with the popular games
+		source := "personalized"
+		if len(ids) == 0 {
+			source = "popular"
+		}
+		if len(ids) < limit {
+			popular, err := trendingGames(app, limit+len(ids))
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+			}
+			played := playedGameCounts(app, authRecord.Id)
+			for _, g := range popular {
+				if len(ids) >= limit {
+					break
+				}
+				if _, ok := played[g.Id]; !ok && !slices.Contains(ids, g.Id) {
+					ids = append(ids, g.Id)
+				}
+			}
+		}
+
+		games, err := findGamesInOrder(app, ids)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		localizeRecords(games, requestLang(re))
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items":  games,
+			"source": source,
+		})
+	})
+}
+
+// registerRecommendationJobs rebuilds the item-to-item co-occurrence every hour.
+func registerRecommendationJobs(app core.App) {
+	app.Cron().MustAdd("computeGameSimilarities", "30 * * * *", func() {
+		if err := computeGameSimilarities(app); err != nil {
+			log.Println("Game similarities error:", err)
+		}
+	})
+}
+
+// playedGameCounts returns the number of sessions per game of the user in the history window.
+func playedGameCounts(app core.App, userId string) map[string]int {
+	var rows []struct {
+		Game  string `db:"game"`
+		Plays int    `db:"plays"`
+	}
+	err := app.DB().
+		Select("game", "COUNT(*) AS plays").
+		From("game_sessions").
+		Where(dbx.HashExp{"user": userId}).
+		AndWhere(dbx.NewExp("created >= {:since}", dbx.Params{
+			"since": time.Now().UTC().Add(-recommendHistoryWindow).Format(types.DefaultDateLayout),
+		})).
+		GroupBy("game").
+		All(&rows)
+
+	counts := make(map[string]int, len(rows))
+	if err != nil {
+		return counts
+	}
+	for _, row := range rows {
+		counts[row.Game] = row.Plays
+	}
+	return counts
+}
+
+// recommendedGameIds ranks the active games the user hasn't played yet by
+// the games similar to the played ones, the affinity for their categories
+// and their freshness. Games without any personal signal are left out.
+func recommendedGameIds(app core.App, userId string, limit int) ([]string, error) {
+	played := playedGameCounts(app, userId)
+	if len(played) == 0 {
+		return nil, nil
+	}
+
+	games, err := app.FindRecordsByFilter("games", "is_active = true", "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Category affinity, share of the plays per category
+	affinity := map[string]float64{}
+	totalPlays := 0.0
+	for _, g := range games {
+		plays, ok := played[g.Id]
+		if !ok {
+			continue
+		}
+		affinity[g.GetString("category")] += float64(plays)
+		for _, tag := range g.GetStringSlice("tags") {
+			affinity[tag] += recommendTagWeight * float64(plays)
+		}
+		totalPlays += float64(plays)
+	}
+	for category := range affinity {
+		affinity[category] /= totalPlays
+	}
+
+	// 2. Co-occurrence, similar games weighted by how much the source game was played
+	playedIds := make([]any, 0, len(played))
+	for id := range played {
+		playedIds = append(playedIds, id)
+	}
+	var similar []struct {
+		Game        string  `db:"game"`
+		SimilarGame string  `db:"similar_game"`
+		Score       float64 `db:"score"`
+	}
+	err = app.DB().
+		Select("game", "similar_game", "score").
+		From("game_similarities").
+		Where(dbx.In("game", playedIds...)).
+		All(&similar)
+	if err != nil {
+		return nil, err
+	}
+	coOccurrence := map[string]float64{}
+	maxCoOccurrence := 0.0
+	for _, s := range similar {
+		coOccurrence[s.SimilarGame] += s.Score * math.Log1p(float64(played[s.Game]))
+		maxCoOccurrence = max(maxCoOccurrence, coOccurrence[s.SimilarGame])
+	}
+
+	// 3. Rank the unplayed games
+	type candidate struct {
+		Id    string
+		Score float64
+	}
+	now := time.Now().UTC()
+	candidates := make([]candidate, 0, len(games))
+	for _, g := range games {
+		if _, ok := played[g.Id]; ok {
+			continue
+		}
+
+		co := 0.0
+		if maxCoOccurrence > 0 {
+			co = coOccurrence[g.Id] / maxCoOccurrence
+		}
+
+		aff := affinity[g.GetString("category")]
+		for _, tag := range g.GetStringSlice("tags") {
+			aff += recommendTagWeight * affinity[tag]
+		}
+		aff = min(aff, 1)
+
+		if co == 0 && aff == 0 {
+			continue
+		}
+
+		ageDays := now.Sub(g.GetDateTime("created").Time()).Hours() / 24
+		fresh := math.Exp(-max(ageDays, 0) / recommendFreshnessDays)
+
+		candidates = append(candidates, candidate{
+			Id: g.Id,
+			Score: recommendCoOccurrenceWeight*co +
+				recommendAffinityWeight*aff +
+				recommendFreshnessWeight*fresh,
+		})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	ids := make([]string, 0, limit)
+	for _, c := range candidates[:min(limit, len(candidates))] {
+		ids = append(ids, c.Id)
+	}
+
+	return ids, nil
+}
+
+type gamePair struct {
+	Game        string  `db:"game"`
+	SimilarGame string  `db:"similar_game"`
+	CoPlayers   int     `db:"co_players"`
+	PlayersA    int     `db:"players_a"`
+	PlayersB    int     `db:"players_b"`
+	Score       float64 `db:"-"`
+}
+
+// computeGameSimilarities replaces the game_similarities with the cosine
+// similarity of the players of every pair of games (within the history window).
+func computeGameSimilarities(app core.App) error {
+	var pairs []gamePair
+	err := app.DB().NewQuery(`
+		WITH plays AS (
+			SELECT DISTINCT user, game FROM game_sessions
+			WHERE created >= {:since} AND status != 'flagged'
+		),
+		totals AS (
+			SELECT game, COUNT(*) AS players FROM plays GROUP BY game
+		)
+		SELECT a.game AS game, b.game AS similar_game, COUNT(*) AS co_players,
+			ta.players AS players_a, tb.players AS players_b
+		FROM plays a
+		INNER JOIN plays b ON b.user = a.user AND b.game != a.game
+		INNER JOIN totals ta ON ta.game = a.game
+		INNER JOIN totals tb ON tb.game = b.game
+		GROUP BY a.game, b.game
+		HAVING COUNT(*) >= {:minCoPlayers}
+	`).Bind(dbx.Params{
+		"since":        time.Now().UTC().Add(-recommendHistoryWindow).Format(types.DefaultDateLayout),
+		"minCoPlayers": similarityMinCoPlayers,
+	}).All(&pairs)
+	if err != nil {
+		return err
+	}
+
+	for i := range pairs {
+		pairs[i].Score = float64(pairs[i].CoPlayers) / math.Sqrt(float64(pairs[i].PlayersA*pairs[i].PlayersB))
+	}
+
+	// best first per game, then keep the top K of each
+	slices.SortStableFunc(pairs, func(a, b gamePair) int {
+		if c := cmp.Compare(a.Game, b.Game); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		if _, err := txApp.DB().NewQuery("DELETE FROM game_similarities").Execute(); err != nil {
+			return err
+		}
+
+		collection, err := txApp.FindCollectionByNameOrId("game_similarities")
+		if err != nil {
+			return err
+		}
+
+		kept := map[string]int{}
+		for _, p := range pairs {
+			if kept[p.Game] >= similarityTopK {
+				continue
+			}
+			kept[p.Game]++
+
+			record := core.NewRecord(collection)
+			record.Set("game", p.Game)
+			record.Set("similar_game", p.SimilarGame)
+			record.Set("score", math.Round(p.Score*10000)/10000)
+			record.Set("co_players", p.CoPlayers)
+			if err := txApp.Save(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+//go:embed static/score-bridge.js
+var scoreBridgeJS []byte
+
+const (
+	// how far the signed client timestamp may drift from the server clock
+	scoreMaxClockSkew = 5 * time.Minute
+	// scores reported right before the player closes the game are still accepted
+	scoreEndedGrace = 2 * time.Minute
+	// hard limit of accepted submissions per session
+	scoreMaxPerSession = 500
+	// sanity upper bound for a single score
+	scoreMaxValue = 1_000_000_000
+
+	gameLeaderboardSize = 50
+)
+
+type scoreSubmission struct {
+	SessionId string `json:"session_id"`
+	Score     int64  `json:"score"`
+	Level     int64  `json:"level"`
+	Ts        int64  `json:"ts"`
+	Signature string `json:"signature"`
+}
+
+func registerScoreRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Score Bridge script (injected into the game WebView)
+	e.Router.GET("/api/score-bridge.js", func(re *core.RequestEvent) error {
+		re.Response.Header().Set("Cache-Control", "public, max-age=3600")
+		return re.Blob(http.StatusOK, "application/javascript; charset=utf-8", scoreBridgeJS)
+	})
+
+	// ROUTE: Submit Game Score
+	e.Router.POST("/api/games/{id}/scores", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		var body scoreSubmission
+		if err := re.BindBody(&body); err != nil {
+			return apiError(re, http.StatusBadRequest, "invalid_request", err)
+		}
+
+		session, err := app.FindRecordById("game_sessions", body.SessionId)
+		if err != nil ||
+			session.GetString("user") != authRecord.Id ||
+			session.GetString("game") != re.Request.PathValue("id") {
+			return apiError(re, http.StatusNotFound, "session_not_found", err)
+		}
+
+		now := time.Now().UTC()
+		if !sessionAcceptsScores(session, now) {
+			return apiError(re, http.StatusBadRequest, "session_not_active", nil)
+		}
+
+		if !verifyScoreSignature(session, body) {
+			return apiError(re, http.StatusForbidden, "invalid_score_signature", nil)
+		}
+
+		if body.Score < 0 || body.Score > scoreMaxValue || body.Level < 0 {
+			return apiError(re, http.StatusBadRequest, "invalid_score", nil)
+		}
+
+		if d := now.Sub(time.UnixMilli(body.Ts)); d > scoreMaxClockSkew || d < -scoreMaxClockSkew {
+			return apiError(re, http.StatusBadRequest, "score_timestamp_out_of_range", nil)
+		}
+
+		// Replay protection: timestamps must keep increasing within the session
+		var lastTs int64
+		var submitted int
+		err = app.DB().
+			Select("COALESCE(MAX(client_ts), 0)", "COUNT(*)").
+			From("game_scores").
+			Where(dbx.HashExp{"session": session.Id}).
+			Row(&lastTs, &submitted)
+		if err != nil {
+			return err
+		}
+		if body.Ts <= lastTs {
+			return apiError(re, http.StatusBadRequest, "score_already_submitted", nil)
+		}
+		if submitted >= scoreMaxPerSession {
+			return apiError(re, http.StatusTooManyRequests, "too_many_scores", nil)
+		}
+
+		scoresCollection, err := app.FindCollectionByNameOrId("game_scores")
+		if err != nil {
+			return err
+		}
+
+		score := core.NewRecord(scoresCollection)
+		score.Set("user", authRecord.Id)
+		score.Set("game", session.GetString("game"))
+		score.Set("session", session.Id)
+		score.Set("score", body.Score)
+		score.Set("level", body.Level)
+		score.Set("client_ts", body.Ts)
+
+		if err := app.Save(score); err != nil {
+			return apiError(re, http.StatusBadRequest, "save_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success": true,
+			"score":   body.Score,
+			"level":   body.Level,
+		})
+	})
+
+	// ROUTE: Per-Game Leaderboard (?period=all_time|weekly)
+	e.Router.GET("/api/games/{id}/leaderboard", func(re *core.RequestEvent) error {
+		game, err := app.FindRecordById("games", re.Request.PathValue("id"))
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "game_not_found", err)
+		}
+
+		period := re.Request.URL.Query().Get("period")
+		if period == "" {
+			period = "all_time"
+		}
+
+		params := dbx.Params{"game": game.Id, "limit": gameLeaderboardSize}
+		switch period {
+		case "all_time":
+			params["since"] = ""
+		case "weekly":
+			params["since"] = startOfWeek(time.Now().UTC()).Format(types.DefaultDateLayout)
+		default:
+			return apiError(re, http.StatusBadRequest, "unknown_period", nil)
+		}
+
+		// best score of every player and when they first reached it (the
+		// earlier achievers first on ties)
+		bests := `
+			WITH bests AS (
+				SELECT
+					user, score, created AS reached_at,
+					ROW_NUMBER() OVER (PARTITION BY user ORDER BY score DESC, created ASC) AS n
+				FROM game_scores
+				WHERE game = {:game} AND created >= {:since}
+			)
+		`
+
+		var rows []struct {
+			UserId    string `db:"user_id"`
+			Best      int    `db:"best"`
+			Username  string `db:"username"`
+			AvatarUrl string `db:"avatar_url"`
+		}
+		err = app.DB().NewQuery(bests + `
+			SELECT b.user AS user_id, b.score AS best, u.username, u.avatar_url
+			FROM bests b
+			LEFT JOIN users u ON u.id = b.user
+			WHERE b.n = 1
+			ORDER BY b.score DESC, b.reached_at ASC, b.user ASC
+			LIMIT {:limit}
+		`).Bind(params).All(&rows)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		type GameLeaderboardItem struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+			Avatar   string `json:"avatar"`
+			Score    int    `json:"score"`
+			Rank     int    `json:"rank"`
+		}
+
+		leaderboard := make([]GameLeaderboardItem, 0, len(rows))
+		for i, row := range rows {
+			leaderboard = append(leaderboard, GameLeaderboardItem{
+				ID:       row.UserId,
+				Username: row.Username,
+				Avatar:   row.AvatarUrl,
+				Score:    row.Best,
+				Rank:     i + 1,
+			})
+		}
+
+		// Current user's best score and rank, with the same ordering
+		var userBest, userRank int
+		if re.Auth != nil {
+			params["user"] = re.Auth.Id
+
+			var own struct {
+				Score     int    `db:"score"`
+				ReachedAt string `db:"reached_at"`
+			}
+			err := app.DB().NewQuery(bests + `
+				SELECT score, reached_at FROM bests WHERE n = 1 AND user = {:user}
+			`).Bind(params).One(&own)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				// no score in the period
+			case err != nil:
+				return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+			default:
+				params["score"] = own.Score
+				params["reachedAt"] = own.ReachedAt
+
+				var ahead int
+				err := app.DB().NewQuery(bests + `
+					SELECT COUNT(*) FROM bests
+					WHERE n = 1 AND (
+						score > {:score}
+						OR (score = {:score} AND reached_at < {:reachedAt})
+						OR (score = {:score} AND reached_at = {:reachedAt} AND user < {:user})
+					)
+				`).Bind(params).Row(&ahead)
+				if err != nil {
+					return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+				}
+
+				userBest = own.Score
+				userRank = ahead + 1
+			}
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"period":      period,
+			"leaderboard": leaderboard,
+			"user_best":   userBest,
+			"user_rank":   userRank,
+		})
+	})
+}
+
+// sessionAcceptsScores reports whether scores can still be attached to the session.
+func sessionAcceptsScores(session *core.Record, now time.Time) bool {
+	switch session.GetString("status") {
+	case "active":
+		return true
+	case "ended":
+		return now.Sub(session.GetDateTime("ended_at").Time()) <= scoreEndedGrace
+	default:
+		return false
+	}
+}
+
+// verifyScoreSignature checks the HMAC-SHA256 of "sessionId.score.level.ts"
+// made with the session score key (see static/score-bridge.js).
+func verifyScoreSignature(session *core.Record, body scoreSubmission) bool {
+	key := session.GetString("score_key")
+	if key == "" {
+		return false
+	}
+
+	signature, err := hex.DecodeString(body.Signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	fmt.Fprintf(mac, "%s.%d.%d.%d", session.Id, body.Score, body.Level, body.Ts)
+
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+// startOfWeek returns Monday 00:00 UTC of the week of t.
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC().Truncate(24 * time.Hour)
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// max number of FTS matches that are ranked, faceted and paginated
+	searchMaxCandidates  = 200
+	searchMaxTerms       = 8
+	searchDefaultPerPage = 20
+	searchMaxPerPage     = 50
+
+	// popularity (plays in the window) boosts the text relevance by up to ~x2-3
+	searchPopularityWeight = 0.3
+	searchPopularityWindow = 30 * 24 * time.Hour
+)
+
+var searchTokenRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// registerSearchHooks keeps the games_fts index in sync with the catalog.
+func registerSearchHooks(app core.App) {
+	app.OnRecordAfterCreateSuccess("games").BindFunc(func(e *core.RecordEvent) error {
+		if err := indexGameForSearch(e.App, e.Record); err != nil {
+			log.Printf("ERROR indexing game %s: %v", e.Record.Id, err)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordAfterUpdateSuccess("games").BindFunc(func(e *core.RecordEvent) error {
+		if err := indexGameForSearch(e.App, e.Record); err != nil {
+			log.Printf("ERROR indexing game %s: %v", e.Record.Id, err)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordAfterDeleteSuccess("games").BindFunc(func(e *core.RecordEvent) error {
+		_, err := e.App.DB().Delete("games_fts", dbx.HashExp{"game_id": e.Record.Id}).Execute()
+		if err != nil {
+			log.Printf("ERROR removing game %s from the search index: %v", e.Record.Id, err)
+		}
+		return e.Next()
+	})
+
+	// renamed categories change the indexed text of all their games
+	app.OnRecordAfterUpdateSuccess("game_categories").BindFunc(func(e *core.RecordEvent) error {
+		games, err := e.App.FindRecordsByFilter(
+			"games",
+			"category = {:id} || tags ~ {:id}",
+			"", 0, 0,
+			map[string]any{"id": e.Record.Id},
+		)
+		if err == nil {
+			for _, game := range games {
+				if err := indexGameForSearch(e.App, game); err != nil {
+					log.Printf("ERROR indexing game %s: %v", game.Id, err)
+				}
+			}
+		}
+		return e.Next()
+	})
+}
+
+func registerSearchRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Full-Text Game Search (?q=&category=&favorites=&page=&perPage=&lang=)
+	e.Router.GET("/api/games/search", func(re *core.RequestEvent) error {
+		query := re.Request.URL.Query()
+
+		terms := searchTerms(query.Get("q"))
+		if len(terms) == 0 {
+			return apiError(re, http.StatusBadRequest, "missing_search_query", nil)
+		}
+
+		page, _ := strconv.Atoi(query.Get("page"))
+		page = max(page, 1)
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+		if perPage <= 0 {
+			perPage = searchDefaultPerPage
+		}
+		perPage = min(perPage, searchMaxPerPage)
+
+		matches, err := searchGames(app, terms)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		// Nothing found, retry once with the typos corrected against the indexed terms
+		correctedQuery := ""
+		if len(matches) == 0 {
+			if corrected := correctSearchTerms(app, terms); !slices.Equal(corrected, terms) {
+				if matches, err = searchGames(app, corrected); err != nil {
+					return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+				}
+				correctedQuery = strings.Join(corrected, " ")
+			}
+		}
+
+		ids := make([]string, 0, len(matches))
+		for _, m := range matches {
+			ids = append(ids, m.GameId)
+		}
+
+		records, err := app.FindRecordsByIds("games", ids)
+		if err != nil {
+			return err
+		}
+		recordsById := make(map[string]*core.Record, len(records))
+		for _, r := range records {
+			recordsById[r.Id] = r
+		}
+
+		// Rank by relevance and popularity
+		plays := recentPlayCounts(app, ids)
+		for i := range matches {
+			relevance := -matches[i].Bm25 // bm25() is negative, lower is better
+			matches[i].Score = relevance * (1 + searchPopularityWeight*math.Log1p(float64(plays[matches[i].GameId])))
+		}
+		slices.SortStableFunc(matches, func(a, b searchMatch) int {
+			switch {
+			case a.Score > b.Score:
+				return -1
+			case a.Score < b.Score:
+				return 1
+			default:
+				return 0
+			}
+		})
+
+		// Category facets are computed before the category filter is applied,
+		// a game counts for its main category and each of its tags
+		facetCounts := map[string]int{}
+		ranked := make([]*core.Record, 0, len(matches))
+		for _, m := range matches {
+			r, ok := recordsById[m.GameId]
+			if !ok {
+				continue
+			}
+			categoryIds := append([]string{r.GetString("category")}, r.GetStringSlice("tags")...)
+			slices.Sort(categoryIds)
+			for _, id := range slices.Compact(categoryIds) {
+				if id != "" {
+					facetCounts[id]++
+				}
+			}
+			ranked = append(ranked, r)
+		}
+
+		facets, err := searchFacets(app, facetCounts, requestLang(re))
+		if err != nil {
+			return err
+		}
+
+		// main category or any of the tags
+		if slug := query.Get("category"); slug != "" {
+			category, err := app.FindFirstRecordByData("game_categories", "slug", slug)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "unknown_category", err)
+			}
+			ranked = slices.DeleteFunc(ranked, func(r *core.Record) bool {
+				return r.GetString("category") != category.Id && !slices.Contains(r.GetStringSlice("tags"), category.Id)
+			})
+		}
+
+		// ?favorites=true limits the results to the user's favorites
+		if query.Get("favorites") == "true" && re.Auth != nil {
+			favoriteIds := userFavoriteGameIds(app, re.Auth.Id)
+			ranked = slices.DeleteFunc(ranked, func(r *core.Record) bool {
+				return !slices.Contains(favoriteIds, r.Id)
+			})
+		}
+
+		totalItems := len(ranked)
+		start := min((page-1)*perPage, totalItems)
+		end := min(start+perPage, totalItems)
+		items := ranked[start:end]
+
+		if errs := app.ExpandRecords(items, []string{"category"}, nil); len(errs) > 0 {
+			log.Println("Search expand errors:", errs)
+		}
+		localizeRecords(items, requestLang(re))
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items":           items,
+			"page":            page,
+			"perPage":         perPage,
+			"totalItems":      totalItems,
+			"facets":          facets,
+			"corrected_query": correctedQuery,
+		})
+	})
+}
+
+type searchMatch struct {
+	GameId string  `db:"game_id"`
+	Bm25   float64 `db:"bm25"`
+	Score  float64 `db:"-"`
+}
+
+type searchFacet struct {
+	ID    string `json:"id"`
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// searchTerms extracts the lowercased words of a user query.
+func searchTerms(q string) []string {
+	terms := searchTokenRegex.FindAllString(strings.ToLower(q), searchMaxTerms)
+	return slices.Compact(terms)
+}
+
+// searchGames runs a prefix match of all terms against the active games.
+func searchGames(app core.App, terms []string) ([]searchMatch, error) {
+	quoted := make([]string, 0, len(terms))
+	for _, t := range terms {
+		quoted = append(quoted, `"`+t+`"*`)
+	}
+
+	var matches []searchMatch
+	err := app.DB().NewQuery(`
+		SELECT f.game_id, bm25(games_fts, 0.0, 10.0, 2.0, 4.0, 8.0) AS bm25
+		FROM games_fts f
+		INNER JOIN games g ON g.id = f.game_id
+		WHERE games_fts MATCH {:match} AND g.is_active = 1
+		ORDER BY bm25
+		LIMIT {:limit}
+	`).Bind(dbx.Params{
+		"match": strings.Join(quoted, " "),
+		"limit": searchMaxCandidates,
+	}).All(&matches)
+
+	return matches, err
+}
+
+// correctSearchTerms replaces the terms that don't match any indexed word
+// (not even as a prefix) with the closest indexed one.
+func correctSearchTerms(app core.App, terms []string) []string {
+	var vocab []string
+	if err := app.DB().NewQuery("SELECT term FROM games_fts_vocab").Column(&vocab); err != nil {
+		return terms
+	}
+
+	corrected := make([]string, len(terms))
+	for i, term := range terms {
+		corrected[i] = term
+		if slices.ContainsFunc(vocab, func(v string) bool { return strings.HasPrefix(v, term) }) {
+			continue
+		}
+
+		maxDistance := 1
+		if len([]rune(term)) > 4 {
+			maxDistance = 2
+		}
+
+		best, bestDistance := "", maxDistance+1
+		for _, v := range vocab {
+			if d := levenshtein(term, v); d < bestDistance {
+				best, bestDistance = v, d
+			}
+		}
+		if best != "" {
+			corrected[i] = best
+		}
+	}
+
+	return corrected
+}
+
+// recentPlayCounts returns the number of sessions per game in the popularity window.
+func recentPlayCounts(app core.App, gameIds []string) map[string]int {
+	counts := make(map[string]int, len(gameIds))
+	if len(gameIds) == 0 {
+		return counts
+	}
+
+	ids := make([]any, len(gameIds))
+	for i, id := range gameIds {
+		ids[i] = id
+	}
+
+	var rows []struct {
+		Game  string `db:"game"`
+		Plays int    `db:"plays"`
+	}
+	err := app.DB().
+		Select("game", "COUNT(*) AS plays").
+		From("game_sessions").
+		Where(dbx.In("game", ids...)).
+		AndWhere(dbx.NewExp("created >= {:since}", dbx.Params{
+			"since": time.Now().UTC().Add(-searchPopularityWindow).Format(types.DefaultDateLayout),
+		})).
+		GroupBy("game").
+		All(&rows)
+	if err != nil {
+		return counts
+	}
+
+	for _, row := range rows {
+		counts[row.Game] = row.Plays
+	}
+
+	return counts
+}
+
+func searchFacets(app core.App, counts map[string]int, lang string) ([]searchFacet, error) {
+	facets := []searchFacet{}
+
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return facets, nil
+	}
+
+	categories, err := app.FindRecordsByIds("game_categories", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range categories {
+		names := map[string]string{}
+		_ = c.UnmarshalJSONField("names", &names)
+
+		facets = append(facets, searchFacet{
+			ID:    c.Id,
+			Slug:  c.GetString("slug"),
+			Name:  localized(names, lang, c.GetString("slug")),
+			Count: counts[c.Id],
+		})
+	}
+
+	slices.SortFunc(facets, func(a, b searchFacet) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Slug, b.Slug)
+	})
+
+	return facets, nil
+}
+
+// indexGameForSearch (re)writes the games_fts row of a single game.
+func indexGameForSearch(app core.App, game *core.Record) error {
+	// category slugs and names in all languages
+	var categoryText []string
+	categoryIds := append([]string{game.GetString("category")}, game.GetStringSlice("tags")...)
+	categoryIds = slices.DeleteFunc(slices.Compact(categoryIds), func(id string) bool { return id == "" })
+	if len(categoryIds) > 0 {
+		categories, err := app.FindRecordsByIds("game_categories", categoryIds)
+		if err != nil {
+			return err
+		}
+		for _, c := range categories {
+			names := map[string]string{}
+			_ = c.UnmarshalJSONField("names", &names)
+
+			categoryText = append(categoryText, c.GetString("slug"))
+			for _, name := range names {
+				categoryText = append(categoryText, name)
+			}
+		}
+	}
+
+	// localized titles and descriptions
+	var localizedText []string
+	translations := map[string]map[string]string{}
+	_ = game.UnmarshalJSONField("translations", &translations)
+	for _, t := range translations {
+		localizedText = append(localizedText, t["title"], t["description"])
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		if _, err := txApp.DB().Delete("games_fts", dbx.HashExp{"game_id": game.Id}).Execute(); err != nil {
+			return err
+		}
+
+		_, err := txApp.DB().Insert("games_fts", dbx.Params{
+			"game_id":     game.Id,
+			"title":       game.GetString("title"),
+			"description": game.GetString("description"),
+			"category":    strings.Join(categoryText, " "),
+			"localized":   strings.Join(localizedText, " "),
+		}).Execute()
+
+		return err
+	})
+}
+
+// rebuildGamesSearchIndex reindexes the whole catalog.
+func rebuildGamesSearchIndex(app core.App) error {
+	games, err := app.FindAllRecords("games")
+	if err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		if _, err := txApp.DB().NewQuery("DELETE FROM games_fts").Execute(); err != nil {
+			return err
+		}
+		for _, game := range games {
+			if err := indexGameForSearch(txApp, game); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// levenshtein returns the edit distance between a and b (in runes).
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
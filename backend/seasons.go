@@ -0,0 +1,318 @@
+package main
+
+import (
+	"log"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// players archived with every season
+	seasonStandingsSize = 100
+
+	seasonsDefaultPerPage = 10
+	seasonsMaxPerPage     = 50
+)
+
+// seasonPeriods are the leaderboard periods that end as a season
+// (all-time never resets).
+var seasonPeriods = []string{"daily", "weekly", "monthly"}
+
+// defaultSeasonRewards are the coins per period and rank bracket when
+// leaderboard_rewards_config has no entry for them.
+var defaultSeasonRewards = map[string]map[string]int{
+	"daily":   {"1": 200, "2-3": 100, "4-10": 50, "top_1_percent": 20},
+	"weekly":  {"1": 1000, "2-3": 500, "4-10": 200, "top_1_percent": 100},
+	"monthly": {"1": 5000, "2-3": 2500, "4-10": 1000, "top_1_percent": 500},
+}
+
+// seasonStanding is a player's final result, as archived in leaderboard_seasons.standings.
+type seasonStanding struct {
+	Rank     int    `json:"rank"`
+	User     string `json:"user"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar"`
+	Score    int    `json:"score"`
+	Reward   int    `json:"reward"`
+}
+
+func registerSeasonRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Past Leaderboard Seasons (?period=daily|weekly|monthly&page=&perPage=), newest first
+	e.Router.GET("/api/leaderboard/seasons", func(re *core.RequestEvent) error {
+		query := re.Request.URL.Query()
+
+		page, _ := strconv.Atoi(query.Get("page"))
+		page = max(page, 1)
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+		if perPage <= 0 {
+			perPage = seasonsDefaultPerPage
+		}
+		perPage = min(perPage, seasonsMaxPerPage)
+
+		filter := "1=1"
+		params := dbx.Params{}
+		if period := query.Get("period"); period != "" {
+			if !slices.Contains(seasonPeriods, period) {
+				return apiError(re, http.StatusBadRequest, "unknown_period", nil)
+			}
+			filter = "period = {:period}"
+			params["period"] = period
+		}
+
+		totalItems, err := app.CountRecords("leaderboard_seasons", dbx.NewExp(filter, params))
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		seasons, err := app.FindRecordsByFilter(
+			"leaderboard_seasons",
+			filter,
+			"-ends_at,period",
+			perPage, (page-1)*perPage,
+			params,
+		)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		items := make([]map[string]any, 0, len(seasons))
+		for _, season := range seasons {
+			standings := seasonStandings(season)
+			item := seasonPayload(season, standings, re.Auth)
+			item["winners"] = standings[:min(3, len(standings))]
+			items = append(items, item)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items":      items,
+			"page":       page,
+			"perPage":    perPage,
+			"totalItems": totalItems,
+		})
+	})
+
+	// ROUTE: Leaderboard Season (final standings)
+	e.Router.GET("/api/leaderboard/seasons/{id}", func(re *core.RequestEvent) error {
+		season, err := app.FindRecordById("leaderboard_seasons", re.Request.PathValue("id"))
+		if err != nil {
+			return apiError(re, http.StatusNotFound, "season_not_found", err)
+		}
+
+		standings := seasonStandings(season)
+		item := seasonPayload(season, standings, re.Auth)
+		item["standings"] = standings
+
+		return re.JSON(http.StatusOK, item)
+	})
+}
+
+// registerSeasonJobs closes the ended daily/weekly/monthly seasons
+// shortly after midnight (UTC).
+func registerSeasonJobs(app core.App) {
+	app.Cron().MustAdd("closeLeaderboardSeasons", "5 0 * * *", func() {
+		if err := closeLeaderboardSeasons(app, time.Now().UTC()); err != nil {
+			log.Println("Leaderboard seasons error:", err)
+		}
+	})
+}
+
+// closeLeaderboardSeasons archives the ended seasons of every period that
+// aren't archived yet, oldest first, paying their rewards and notifying the
+// winners. All the ones since the last archived season are closed (the job
+// may not have run for a few days), before the first one only the last
+// ended season is.
+func closeLeaderboardSeasons(app core.App, now time.Time) error {
+	for _, period := range seasonPeriods {
+		var lastStart time.Time
+		last, err := app.FindRecordsByFilter(
+			"leaderboard_seasons",
+			"period = {:period}",
+			"-starts_at",
+			1, 0,
+			dbx.Params{"period": period},
+		)
+		if err != nil {
+			return err
+		}
+		if len(last) > 0 {
+			lastStart = last[0].GetDateTime("starts_at").Time()
+		}
+
+		// the ended windows after the last archived one, newest first
+		var starts, ends []time.Time
+		next, _, _ := leaderboardWindow(period, now)
+		for {
+			start, end, _ := leaderboardWindow(period, next.Add(-time.Second))
+			if !start.After(lastStart) {
+				break
+			}
+			starts, ends = append(starts, start), append(ends, end)
+			if lastStart.IsZero() {
+				break
+			}
+			next = start
+		}
+
+		for i := len(starts) - 1; i >= 0; i-- {
+			if err := closeLeaderboardSeason(app, period, starts[i], ends[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func closeLeaderboardSeason(app core.App, period string, start time.Time, end time.Time) error {
+	players, err := leaderboardPlayers(app, period, start)
+	if err != nil {
+		return err
+	}
+
+	// everyone in the top 1% is paid, even past the archived standings
+	topPercent := int(math.Ceil(float64(players) / 100))
+	rows, err := leaderboardStandings(app, period, start, leaderboardFilter{}, 0, max(seasonStandingsSize, topPercent))
+	if err != nil {
+		return err
+	}
+
+	rewards := loadSeasonRewards(app, period)
+	standings := make([]seasonStanding, 0, len(rows))
+	for i, row := range rows {
+		rank := i + 1
+		standings = append(standings, seasonStanding{
+			Rank:     rank,
+			User:     row.UserId,
+			Username: row.Username,
+			Avatar:   row.AvatarUrl,
+			Score:    row.Score,
+			Reward:   rewards[seasonBracket(rank, topPercent)],
+		})
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		seasonsCollection, err := txApp.FindCollectionByNameOrId("leaderboard_seasons")
+		if err != nil {
+			return err
+		}
+
+		season := core.NewRecord(seasonsCollection)
+		season.Set("period", period)
+		season.Set("starts_at", start)
+		season.Set("ends_at", end)
+		season.Set("players", players)
+		season.Set("standings", standings[:min(seasonStandingsSize, len(standings))])
+		if err := txApp.Save(season); err != nil {
+			return err
+		}
+
+		notificationsCollection, err := txApp.FindCollectionByNameOrId("notifications")
+		if err != nil {
+			return err
+		}
+
+		for _, s := range standings {
+			if s.Reward <= 0 {
+				continue
+			}
+
+			user, err := txApp.FindRecordById("users", s.User)
+			if err != nil {
+				return err
+			}
+			if err := creditCoins(txApp, user, s.Reward, "season_reward", season.Id); err != nil {
+				return err
+			}
+
+			notification := core.NewRecord(notificationsCollection)
+			notification.Set("user", s.User)
+			notification.Set("type", "season_reward")
+			notification.Set("data", map[string]any{
+				"season":    season.Id,
+				"period":    period,
+				"starts_at": start.Format(time.RFC3339),
+				"ends_at":   end.Format(time.RFC3339),
+				"rank":      s.Rank,
+				"score":     s.Score,
+				"reward":    s.Reward,
+			})
+			if err := txApp.Save(notification); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// seasonBracket returns the reward bracket of a final rank ("" for none),
+// topPercent being the number of players that make the top 1%.
+func seasonBracket(rank int, topPercent int) string {
+	switch {
+	case rank == 1:
+		return "1"
+	case rank <= 3:
+		return "2-3"
+	case rank <= 10:
+		return "4-10"
+	case rank <= topPercent:
+		return "top_1_percent"
+	}
+
+	return ""
+}
+
+// loadSeasonRewards returns the coins per bracket of a period,
+// leaderboard_rewards_config overriding the defaults.
+func loadSeasonRewards(app core.App, period string) map[string]int {
+	rewards := maps.Clone(defaultSeasonRewards[period])
+
+	configs, err := app.FindRecordsByFilter(
+		"leaderboard_rewards_config",
+		"period = {:period}",
+		"", 0, 0,
+		dbx.Params{"period": period},
+	)
+	if err != nil {
+		return rewards
+	}
+	for _, config := range configs {
+		rewards[config.GetString("bracket")] = config.GetInt("coins")
+	}
+
+	return rewards
+}
+
+func seasonStandings(season *core.Record) []seasonStanding {
+	standings := []seasonStanding{}
+	_ = season.UnmarshalJSONField("standings", &standings)
+
+	return standings
+}
+
+// seasonPayload is the public projection of a season, with the
+// authenticated user's own result (nil if they weren't in the standings).
+func seasonPayload(season *core.Record, standings []seasonStanding, auth *core.Record) map[string]any {
+	var userResult *seasonStanding
+	if auth != nil {
+		if i := slices.IndexFunc(standings, func(s seasonStanding) bool { return s.User == auth.Id }); i >= 0 {
+			userResult = &standings[i]
+		}
+	}
+
+	return map[string]any{
+		"id":          season.Id,
+		"period":      season.GetString("period"),
+		"starts_at":   season.GetDateTime("starts_at").String(),
+		"ends_at":     season.GetDateTime("ends_at").String(),
+		"players":     season.GetInt("players"),
+		"user_result": userResult,
+	}
+}
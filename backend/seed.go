@@ -286,7 +286,20 @@ func seedDatabase(app core.App) error {
 			},
 		}
 
+		// the seeds reference the categories by name, link them by slug
+		categoryIds, err := findCategoryIdsBySlug(app)
+		if err != nil {
+			log.Println("Skipping game categories: collection 'game_categories' does not exist")
+		}
+
 		for _, g := range games {
+			if categoryId, ok := categoryIds[categorySlug(g["category"].(string))]; ok {
+				g["category"] = categoryId
+				g["tags"] = []string{categoryId}
+			} else {
+				delete(g, "category")
+			}
+
 			record := core.NewRecord(gamesCollection)
 			record.Load(g)
 			if err := app.Save(record); err != nil {
@@ -347,6 +360,23 @@ func seedDatabase(app core.App) error {
 		log.Println("✅ Seeded spin prizes")
 	}
 
+	// 4. PLAY REWARDS
+	playRewardsCollection, err := app.FindCollectionByNameOrId("play_rewards_config")
+	if err != nil {
+		log.Println("Skipping Play Rewards seed: collection 'play_rewards_config' does not exist")
+	} else if isCollectionEmpty(app, "play_rewards_config") {
+		record := core.NewRecord(playRewardsCollection)
+		record.Load(map[string]any{
+			"coins_per_minute": defaultCoinsPerMinute,
+			"daily_cap":        defaultDailyPlayCap,
+		})
+		if err := app.Save(record); err != nil {
+			log.Printf("ERROR saving play rewards config: %v", err)
+		} else {
+			log.Println("✅ Seeded play rewards config")
+		}
+	}
+
 	return nil
 }
 
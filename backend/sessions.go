@@ -0,0 +1,333 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// heartbeats closer together than this are ignored so a client can't inflate play time
+	heartbeatMinInterval = 20 * time.Second
+	// gaps longer than this (app in background, lost connection...) don't count as play time
+	heartbeatMaxGap = 90 * time.Second
+	// sessions without a heartbeat for this long are settled by the cron job
+	sessionStaleAfter = 10 * time.Minute
+
+	// bot detection: that many heartbeat intervals with (almost) no variance means a script
+	botMinIntervals = 4
+	botMaxStdDevMs  = 50.0
+
+	// fallbacks when play_rewards_config is empty
+	defaultCoinsPerMinute = 2.0
+	defaultDailyPlayCap   = 200
+)
+
+func registerSessionRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Start Game Session
+	e.Router.POST("/api/games/{id}/sessions", func(re *core.RequestEvent) error {
+		authRecord := re.Auth
+		if authRecord == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		game, err := app.FindRecordById("games", re.Request.PathValue("id"))
+		if err != nil || !game.GetBool("is_active") {
+			return apiError(re, http.StatusNotFound, "game_not_found", err)
+		}
+
+		// Only one session can be active at a time, settle the previous ones first
+		active, err := app.FindRecordsByFilter(
+			"game_sessions",
+			"user = {:user} && status = 'active'",
+			"", 0, 0,
+			map[string]any{"user": authRecord.Id},
+		)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		for _, s := range active {
+			if _, err := endGameSession(app, s.Id, now); err != nil {
+				return err
+			}
+		}
+
+		sessionsCollection, err := app.FindCollectionByNameOrId("game_sessions")
+		if err != nil {
+			return err
+		}
+
+		session := core.NewRecord(sessionsCollection)
+		session.Set("user", authRecord.Id)
+		session.Set("game", game.Id)
+		session.Set("status", "active")
+		session.Set("started_at", now)
+		session.Set("last_heartbeat_at", now)
+		session.Set("heartbeats", []int64{})
+		session.Set("score_key", security.RandomString(32))
+
+		if err := app.Save(session); err != nil {
+			return apiError(re, http.StatusBadRequest, "session_failed", err)
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success":            true,
+			"session_id":         session.Id,
+			"heartbeat_interval": int(heartbeatMinInterval.Seconds()) + 10,
+			"score_key":          session.GetString("score_key"),
+		})
+	})
+
+	// ROUTE: Session Heartbeat
+	e.Router.POST("/api/sessions/{id}/heartbeat", func(re *core.RequestEvent) error {
+		session, err := findOwnActiveSession(app, re)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		lastHeartbeat := session.GetDateTime("last_heartbeat_at").Time()
+		if now.Sub(lastHeartbeat) < heartbeatMinInterval {
+			return re.JSON(http.StatusOK, map[string]any{
+				"success":  true,
+				"accepted": false,
+			})
+		}
+
+		var heartbeats []int64
+		_ = session.UnmarshalJSONField("heartbeats", &heartbeats)
+		heartbeats = append(heartbeats, now.UnixMilli())
+
+		session.Set("heartbeats", heartbeats)
+		session.Set("last_heartbeat_at", now)
+		if err := app.Save(session); err != nil {
+			return err
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success":  true,
+			"accepted": true,
+		})
+	})
+
+	// ROUTE: End Game Session
+	e.Router.POST("/api/sessions/{id}/end", func(re *core.RequestEvent) error {
+		session, err := findOwnActiveSession(app, re)
+		if err != nil {
+			return err
+		}
+
+		session, err = endGameSession(app, session.Id, time.Now().UTC())
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "session_failed", err)
+		}
+
+		user, err := app.FindRecordById("users", re.Auth.Id)
+		if err != nil {
+			return err
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"success":          true,
+			"status":           session.GetString("status"),
+			"verified_seconds": session.GetInt("verified_seconds"),
+			"coins_earned":     session.GetInt("coins_earned"),
+			"coins":            user.GetInt("coins"),
+		})
+	})
+}
+
+// registerSessionJobs settles sessions whose client went away without calling /end.
+func registerSessionJobs(app core.App) {
+	app.Cron().MustAdd("closeStaleGameSessions", "*/10 * * * *", func() {
+		now := time.Now().UTC()
+		stale, err := app.FindRecordsByFilter(
+			"game_sessions",
+			"status = 'active' && last_heartbeat_at < {:before}",
+			"", 0, 0,
+			map[string]any{"before": now.Add(-sessionStaleAfter).Format(types.DefaultDateLayout)},
+		)
+		if err != nil {
+			log.Println("Stale sessions error:", err)
+			return
+		}
+
+		for _, s := range stale {
+			// the session ended at its last heartbeat, not when we noticed it
+			if _, err := endGameSession(app, s.Id, s.GetDateTime("last_heartbeat_at").Time()); err != nil {
+				log.Printf("ERROR closing session %s: %v", s.Id, err)
+			}
+		}
+	})
+}
+
+// findOwnActiveSession loads the {id} session and makes sure it belongs
+// to the authenticated user and is still running.
+func findOwnActiveSession(app core.App, re *core.RequestEvent) (*core.Record, error) {
+	if re.Auth == nil {
+		return nil, apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+	}
+
+	session, err := app.FindRecordById("game_sessions", re.Request.PathValue("id"))
+	if err != nil || session.GetString("user") != re.Auth.Id {
+		return nil, apiError(re, http.StatusNotFound, "session_not_found", err)
+	}
+
+	if session.GetString("status") != "active" {
+		return nil, apiError(re, http.StatusBadRequest, "session_not_active", nil)
+	}
+
+	return session, nil
+}
+
+// endGameSession closes the session at endedAt, computes the verified play time
+// and credits the earned coins and XP to the player. Already closed sessions are returned as is.
+func endGameSession(app core.App, sessionId string, endedAt time.Time) (*core.Record, error) {
+	var session *core.Record
+
+	err := app.RunInTransaction(func(txApp core.App) error {
+		var err error
+		session, err = txApp.FindRecordById("game_sessions", sessionId)
+		if err != nil {
+			return err
+		}
+		if session.GetString("status") != "active" {
+			return nil
+		}
+
+		var heartbeats []int64
+		_ = session.UnmarshalJSONField("heartbeats", &heartbeats)
+
+		points := make([]int64, 0, len(heartbeats)+2)
+		points = append(points, session.GetDateTime("started_at").Time().UnixMilli())
+		points = append(points, heartbeats...)
+		points = append(points, endedAt.UnixMilli())
+
+		verifiedSeconds := verifiedPlaySeconds(points)
+
+		session.Set("ended_at", endedAt)
+		session.Set("verified_seconds", verifiedSeconds)
+
+		if isBotLikeSession(heartbeats) {
+			session.Set("status", "flagged")
+			session.Set("coins_earned", 0)
+			return txApp.Save(session)
+		}
+		session.Set("status", "ended")
+
+		game, err := txApp.FindRecordById("games", session.GetString("game"))
+		if err != nil {
+			return err
+		}
+
+		multiplier := game.GetFloat("reward_multiplier")
+		if multiplier <= 0 {
+			multiplier = 1
+		}
+
+		coinsPerMinute, dailyCap := loadPlayRewardsConfig(txApp)
+		coins := int(math.Floor(float64(verifiedSeconds/60) * coinsPerMinute * multiplier))
+
+		// Respect the daily earning cap
+		earnedToday := playCoinsEarnedToday(txApp, session.GetString("user"), endedAt)
+		coins = max(0, min(coins, dailyCap-earnedToday))
+
+		session.Set("coins_earned", coins)
+		if err := txApp.Save(session); err != nil {
+			return err
+		}
+
+		user, err := txApp.FindRecordById("users", session.GetString("user"))
+		if err != nil {
+			return err
+		}
+
+		if coins > 0 {
+			if err := creditCoins(txApp, user, coins, "play", session.Id); err != nil {
+				return err
+			}
+		}
+
+		// XP per verified minute, not capped like the coins
+		return grantXP(txApp, user, xpReward(txApp, "play")*(verifiedSeconds/60), "play", session.Id)
+	})
+
+	return session, err
+}
+
+// verifiedPlaySeconds sums the gaps between consecutive activity points (unix ms),
+// skipping the ones that are too long to be considered continuous play.
+func verifiedPlaySeconds(points []int64) int {
+	var total int64
+	for i := 1; i < len(points); i++ {
+		gap := points[i] - points[i-1]
+		if gap > 0 && gap <= heartbeatMaxGap.Milliseconds() {
+			total += gap
+		}
+	}
+	return int(total / 1000)
+}
+
+// isBotLikeSession reports whether the heartbeats arrived on a perfectly regular
+// schedule, which a real device on a real network never manages.
+func isBotLikeSession(heartbeats []int64) bool {
+	if len(heartbeats) < botMinIntervals+1 {
+		return false
+	}
+
+	intervals := make([]float64, 0, len(heartbeats)-1)
+	var sum float64
+	for i := 1; i < len(heartbeats); i++ {
+		interval := float64(heartbeats[i] - heartbeats[i-1])
+		intervals = append(intervals, interval)
+		sum += interval
+	}
+
+	mean := sum / float64(len(intervals))
+	var variance float64
+	for _, interval := range intervals {
+		variance += (interval - mean) * (interval - mean)
+	}
+	variance /= float64(len(intervals))
+
+	return math.Sqrt(variance) < botMaxStdDevMs
+}
+
+func loadPlayRewardsConfig(app core.App) (float64, int) {
+	coinsPerMinute := defaultCoinsPerMinute
+	dailyCap := defaultDailyPlayCap
+
+	config, err := app.FindFirstRecordByFilter("play_rewards_config", "1=1")
+	if err == nil && config != nil {
+		coinsPerMinute = config.GetFloat("coins_per_minute")
+		dailyCap = config.GetInt("daily_cap")
+	}
+
+	return coinsPerMinute, dailyCap
+}
+
+// playCoinsEarnedToday returns how many coins the user already got from
+// game sessions on the (UTC) day of t.
+func playCoinsEarnedToday(app core.App, userId string, t time.Time) int {
+	dayStart := t.UTC().Truncate(24 * time.Hour).Format(types.DefaultDateLayout)
+
+	var total int
+	err := app.DB().
+		Select("COALESCE(SUM(coins_earned), 0)").
+		From("game_sessions").
+		Where(dbx.HashExp{"user": userId}).
+		AndWhere(dbx.NewExp("ended_at >= {:dayStart}", dbx.Params{"dayStart": dayStart})).
+		Row(&total)
+	if err != nil {
+		return 0
+	}
+
+	return total
+}
@@ -0,0 +1,150 @@
+// MysteryPlay score bridge.
+//
+// Injected by the game-player WebView into the publisher's game page.
+// It listens to the scores reported by the game (Famobi analytics events
+// or plain postMessage events), signs them with the session key and
+// forwards them to the app, which submits them to /api/games/{id}/scores.
+//
+// The app must define window.__MYSTERYPLAY__ = { sessionId, key } before injecting it.
+(function () {
+  var cfg = window.__MYSTERYPLAY__;
+  if (!cfg || !cfg.sessionId || !cfg.key || window.__MYSTERYPLAY_BRIDGE__) {
+    return;
+  }
+  window.__MYSTERYPLAY_BRIDGE__ = true;
+
+  if (!window.crypto || !window.crypto.subtle || !window.TextEncoder) {
+    return; // can't sign, the server would reject the scores anyway
+  }
+
+  var encoder = new TextEncoder();
+  var keyPromise = window.crypto.subtle.importKey(
+    "raw",
+    encoder.encode(cfg.key),
+    { name: "HMAC", hash: "SHA-256" },
+    false,
+    ["sign"]
+  );
+
+  var currentLevel = 0;
+  var lastTs = 0;
+
+  function toHex(buffer) {
+    var bytes = new Uint8Array(buffer);
+    var out = "";
+    for (var i = 0; i < bytes.length; i++) {
+      out += ("0" + bytes[i].toString(16)).slice(-2);
+    }
+    return out;
+  }
+
+  function toInt(value) {
+    var n = Math.floor(Number(value));
+    return isFinite(n) && n >= 0 ? n : null;
+  }
+
+  function post(message) {
+    var data = JSON.stringify(message);
+    if (window.ReactNativeWebView) {
+      window.ReactNativeWebView.postMessage(data);
+    } else if (window.parent && window.parent !== window) {
+      window.parent.postMessage(data, "*");
+    }
+  }
+
+  function submitScore(score, level) {
+    score = toInt(score);
+    if (score === null) return;
+    level = toInt(level);
+    if (level === null) level = currentLevel;
+
+    // timestamps must be strictly increasing within a session
+    var ts = Math.max(Date.now(), lastTs + 1);
+    lastTs = ts;
+
+    var payload = [cfg.sessionId, score, level, ts].join(".");
+    keyPromise
+      .then(function (key) {
+        return window.crypto.subtle.sign("HMAC", key, encoder.encode(payload));
+      })
+      .then(function (signature) {
+        post({
+          type: "mysteryplay:score",
+          session_id: cfg.sessionId,
+          score: score,
+          level: level,
+          ts: ts,
+          signature: toHex(signature),
+        });
+      })
+      .catch(function () {});
+  }
+
+  // 1. Famobi analytics events
+  function handleFamobiEvent(eventName, params) {
+    params = params || {};
+    switch (eventName) {
+      case "EVENT_LEVELSTART":
+        var level = toInt(params.levelName);
+        if (level !== null) currentLevel = level;
+        break;
+      case "EVENT_LEVELSCORE":
+        submitScore(params.levelScore, toInt(params.levelName));
+        break;
+      case "EVENT_TOTALSCORE":
+        submitScore(params.totalScore);
+        break;
+    }
+  }
+
+  function wrapAnalytics(analytics) {
+    if (!analytics || typeof analytics.trackEvent !== "function" || analytics.__mpWrapped) {
+      return analytics;
+    }
+    var original = analytics.trackEvent;
+    analytics.trackEvent = function (eventName, params) {
+      try {
+        handleFamobiEvent(eventName, params);
+      } catch (e) {}
+      return original.apply(this, arguments);
+    };
+    analytics.__mpWrapped = true;
+    return analytics;
+  }
+
+  var analytics = wrapAnalytics(window.famobi_analytics);
+  try {
+    Object.defineProperty(window, "famobi_analytics", {
+      configurable: true,
+      get: function () {
+        return analytics;
+      },
+      set: function (value) {
+        analytics = wrapAnalytics(value);
+      },
+    });
+  } catch (e) {}
+
+  // 2. Generic postMessage events ({ type: "score" | "level", score, level })
+  window.addEventListener("message", function (event) {
+    var data = event.data;
+    if (typeof data === "string") {
+      try {
+        data = JSON.parse(data);
+      } catch (e) {
+        return;
+      }
+    }
+    if (!data || typeof data !== "object") return;
+
+    if (data.type === "level") {
+      var level = toInt(data.level);
+      if (level !== null) currentLevel = level;
+    } else if (data.type === "score") {
+      submitScore(data.score, data.level);
+    }
+  });
+
+  // 3. Direct API for games we integrate ourselves
+  window.MysteryPlay = { submitScore: submitScore };
+})();
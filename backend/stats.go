@@ -0,0 +1,285 @@
+package main
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// decay of the trending scores, a play counts half after each half-life
+	trending24hHalfLife = 6 * time.Hour
+	trending7dHalfLife  = 48 * time.Hour
+	// a new favorite counts as much as that many fresh plays
+	trendingFavoriteWeight = 5.0
+
+	trendingDefaultLimit = 10
+	trendingMaxLimit     = 50
+
+	catalogDefaultPerPage = 20
+	catalogMaxPerPage     = 50
+)
+
+// catalogSorts maps the ?sort= values of the catalog to their ORDER BY.
+var catalogSorts = map[string][]string{
+	"trending": {"trending_score DESC", "favorite_count DESC", "created DESC"},
+	"popular":  {"play_count DESC", "unique_players DESC", "created DESC"},
+	"new":      {"created DESC"},
+	"rating":   {"rating_avg DESC", "rating_count DESC", "created DESC"},
+}
+
+func registerStatsRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Games Catalog (?sort=trending|popular|new|rating&category=&favorites=&page=&perPage=)
+	e.Router.GET("/api/games", func(re *core.RequestEvent) error {
+		query := re.Request.URL.Query()
+
+		sort := query.Get("sort")
+		if sort == "" {
+			sort = "new"
+		}
+		orderBy, ok := catalogSorts[sort]
+		if !ok {
+			return apiError(re, http.StatusBadRequest, "invalid_sort", nil)
+		}
+
+		page, _ := strconv.Atoi(query.Get("page"))
+		page = max(page, 1)
+		perPage, _ := strconv.Atoi(query.Get("perPage"))
+		if perPage <= 0 {
+			perPage = catalogDefaultPerPage
+		}
+		perPage = min(perPage, catalogMaxPerPage)
+
+		exprs := []dbx.Expression{dbx.HashExp{"is_active": true}}
+
+		// main category or any of the tags
+		if slug := query.Get("category"); slug != "" {
+			category, err := app.FindFirstRecordByData("game_categories", "slug", slug)
+			if err != nil {
+				return apiError(re, http.StatusBadRequest, "unknown_category", err)
+			}
+			exprs = append(exprs, dbx.NewExp(`(
+				category = {:category} OR
+				EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(tags) THEN tags ELSE '[]' END) t WHERE t.value = {:category})
+			)`, dbx.Params{"category": category.Id}))
+		}
+
+		if query.Get("favorites") == "true" {
+			if re.Auth == nil {
+				return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+			}
+			exprs = append(exprs, dbx.NewExp(
+				"id IN (SELECT game FROM user_favorites WHERE user = {:user})",
+				dbx.Params{"user": re.Auth.Id},
+			))
+		}
+
+		totalItems, err := app.CountRecords("games", exprs...)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		var items []*core.Record
+		err = app.RecordQuery("games").
+			AndWhere(dbx.And(exprs...)).
+			OrderBy(append(orderBy, "id ASC")...).
+			Limit(int64(perPage)).
+			Offset(int64((page - 1) * perPage)).
+			All(&items)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		if errs := app.ExpandRecords(items, []string{"category"}, nil); len(errs) > 0 {
+			log.Println("Catalog expand errors:", errs)
+		}
+		localizeRecords(items, requestLang(re))
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items":      items,
+			"page":       page,
+			"perPage":    perPage,
+			"totalItems": totalItems,
+			"totalPages": int(math.Ceil(float64(totalItems) / float64(perPage))),
+			"sort":       sort,
+		})
+	})
+
+	// ROUTE: Trending Games (?limit=)
+	e.Router.GET("/api/games/trending", func(re *core.RequestEvent) error {
+		limit, _ := strconv.Atoi(re.Request.URL.Query().Get("limit"))
+		if limit <= 0 {
+			limit = trendingDefaultLimit
+		}
+		limit = min(limit, trendingMaxLimit)
+
+		games, err := trendingGames(app, limit)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+		localizeRecords(games, requestLang(re))
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"items": games,
+		})
+	})
+}
+
+// registerStatsJobs refreshes the per-game play stats and trending scores.
+func registerStatsJobs(app core.App) {
+	app.Cron().MustAdd("computeGameStats", "*/15 * * * *", func() {
+		if err := computeGameStats(app); err != nil {
+			log.Println("Game stats error:", err)
+		}
+	})
+}
+
+// trendingGames returns the active games with the best trending score.
+func trendingGames(app core.App, limit int) ([]*core.Record, error) {
+	var games []*core.Record
+	err := app.RecordQuery("games").
+		AndWhere(dbx.HashExp{"is_active": true}).
+		OrderBy(append(catalogSorts["trending"], "id ASC")...).
+		Limit(int64(limit)).
+		All(&games)
+	if err != nil {
+		return nil, err
+	}
+
+	if errs := app.ExpandRecords(games, []string{"category"}, nil); len(errs) > 0 {
+		log.Println("Games expand errors:", errs)
+	}
+
+	return games, nil
+}
+
+type gameStats struct {
+	PlayCount         int     `db:"play_count"`
+	UniquePlayers     int     `db:"unique_players"`
+	AvgSessionSeconds float64 `db:"avg_session_seconds"`
+	Plays24h          int     `db:"plays_24h"`
+	Plays7d           int     `db:"plays_7d"`
+	Trending24h       float64 `db:"-"`
+	Trending7d        float64 `db:"-"`
+	Favorites7d       float64 `db:"-"`
+}
+
+// decayed returns the weight of an event that happened age ago.
+func decayed(age, halfLife time.Duration) float64 {
+	return math.Pow(0.5, max(age, 0).Hours()/halfLife.Hours())
+}
+
+// computeGameStats recomputes the play stats of all games from the game
+// sessions (flagged ones excluded). The trending score adds up the plays of
+// the last 24h and of the last 7 days (per day), both time-decayed, and the
+// new favorites of the week.
+func computeGameStats(app core.App) error {
+	now := time.Now().UTC()
+	since24h := now.Add(-24 * time.Hour)
+	since7d := now.Add(-7 * 24 * time.Hour)
+
+	// 1. Totals
+	var totals []struct {
+		Game string `db:"game"`
+		gameStats
+	}
+	err := app.DB().NewQuery(`
+		SELECT game,
+			COUNT(*) AS play_count,
+			COUNT(DISTINCT user) AS unique_players,
+			COALESCE(AVG(CASE WHEN status = 'ended' THEN verified_seconds END), 0) AS avg_session_seconds,
+			SUM(CASE WHEN created >= {:since24h} THEN 1 ELSE 0 END) AS plays_24h,
+			SUM(CASE WHEN created >= {:since7d} THEN 1 ELSE 0 END) AS plays_7d
+		FROM game_sessions
+		WHERE status != 'flagged'
+		GROUP BY game
+	`).Bind(dbx.Params{
+		"since24h": since24h.Format(types.DefaultDateLayout),
+		"since7d":  since7d.Format(types.DefaultDateLayout),
+	}).All(&totals)
+	if err != nil {
+		return err
+	}
+
+	stats := make(map[string]*gameStats, len(totals))
+	for i := range totals {
+		stats[totals[i].Game] = &totals[i].gameStats
+	}
+
+	// 2. Time-decayed plays and favorites of the week
+	var recent []struct {
+		Game     string         `db:"game"`
+		Created  types.DateTime `db:"created"`
+		Favorite bool           `db:"favorite"`
+	}
+	err = app.DB().NewQuery(`
+		SELECT game, created, 0 AS favorite FROM game_sessions
+		WHERE created >= {:since7d} AND status != 'flagged'
+		UNION ALL
+		SELECT game, created, 1 AS favorite FROM user_favorites
+		WHERE created >= {:since7d}
+	`).Bind(dbx.Params{
+		"since7d": since7d.Format(types.DefaultDateLayout),
+	}).All(&recent)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range recent {
+		s, ok := stats[r.Game]
+		if !ok {
+			s = &gameStats{}
+			stats[r.Game] = s
+		}
+
+		age := now.Sub(r.Created.Time())
+		if r.Favorite {
+			s.Favorites7d += decayed(age, trending7dHalfLife)
+			continue
+		}
+		if age <= 24*time.Hour {
+			s.Trending24h += decayed(age, trending24hHalfLife)
+		}
+		s.Trending7d += decayed(age, trending7dHalfLife)
+	}
+
+	// 3. Save, without going through the record hooks (no reindexing, same "updated")
+	var gameIds []string
+	if err := app.DB().Select("id").From("games").Column(&gameIds); err != nil {
+		return err
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		for _, id := range gameIds {
+			s, ok := stats[id]
+			if !ok {
+				s = &gameStats{}
+			}
+
+			trendingScore := s.Trending24h + s.Trending7d/7 + trendingFavoriteWeight*s.Favorites7d
+
+			_, err := txApp.DB().Update("games", dbx.Params{
+				"play_count":          s.PlayCount,
+				"unique_players":      s.UniquePlayers,
+				"avg_session_seconds": math.Round(s.AvgSessionSeconds*10) / 10,
+				"plays_24h":           s.Plays24h,
+				"plays_7d":            s.Plays7d,
+				"trending_24h":        math.Round(s.Trending24h*1000) / 1000,
+				"trending_7d":         math.Round(s.Trending7d*1000) / 1000,
+				"trending_score":      math.Round(trendingScore*1000) / 1000,
+				"stats_updated_at":    now.Format(types.DefaultDateLayout),
+			}, dbx.HashExp{"id": id}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// creditCoins adds amount to the user's coins and records it in the
+// coin_earnings ledger, in one transaction. The other pending changes of
+// user are saved with it.
+func creditCoins(app core.App, user *core.Record, amount int, source string, ref string) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		user.Set("coins", user.GetInt("coins")+amount)
+		if err := txApp.Save(user); err != nil {
+			return err
+		}
+
+		if amount <= 0 {
+			return nil
+		}
+
+		collection, err := txApp.FindCachedCollectionByNameOrId("coin_earnings")
+		if err != nil {
+			return err
+		}
+
+		earning := core.NewRecord(collection)
+		earning.Set("user", user.Id)
+		earning.Set("amount", amount)
+		earning.Set("source", source)
+		earning.Set("ref", ref)
+
+		return txApp.Save(earning)
+	})
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// level-ups listed by /api/me
+const recentLevelUpsLimit = 5
+
+// defaultXPRewards are the XP per source when xp_rewards_config has no entry
+// for it ("play" is per verified minute of a game session).
+var defaultXPRewards = map[string]int{
+	"spin":     5,
+	"check_in": 20,
+	"play":     2,
+	"referral": 100,
+}
+
+// levelStep is a level_curve entry: the total XP the level is reached at
+// and what reaching it gives.
+type levelStep struct {
+	Level       int
+	XPRequired  int
+	RewardCoins int
+	RewardSpins int
+	Unlocks     []string
+}
+
+func registerXPRoutes(app core.App, e *core.ServeEvent) {
+	// ROUTE: Current User (profile, XP progress and the recent level-ups)
+	e.Router.GET("/api/me", func(re *core.RequestEvent) error {
+		user := re.Auth
+		if user == nil {
+			return apiError(re, http.StatusUnauthorized, "unauthenticated", nil)
+		}
+
+		curve, err := loadLevelCurve(app)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		levelUps, err := app.FindRecordsByFilter(
+			"level_ups",
+			"user = {:user}",
+			"-created,-level",
+			recentLevelUpsLimit, 0,
+			dbx.Params{"user": user.Id},
+		)
+		if err != nil {
+			return apiError(re, http.StatusBadRequest, "fetch_failed", err)
+		}
+
+		recentLevelUps := make([]map[string]any, 0, len(levelUps))
+		for _, l := range levelUps {
+			recentLevelUps = append(recentLevelUps, map[string]any{
+				"level":        l.GetInt("level"),
+				"reward_coins": l.GetInt("reward_coins"),
+				"reward_spins": l.GetInt("reward_spins"),
+				"unlocks":      l.Get("unlocks"),
+				"created":      l.GetDateTime("created").String(),
+			})
+		}
+
+		xp := user.GetInt("xp")
+		level := user.GetInt("level")
+
+		// XP at which the current level started and the next one is reached
+		// (nil at the top of the curve)
+		levelXP := 0
+		var nextLevelXP, xpToNextLevel any
+		for _, step := range curve {
+			if step.Level <= level {
+				levelXP = step.XPRequired
+				continue
+			}
+			nextLevelXP = step.XPRequired
+			xpToNextLevel = max(0, step.XPRequired-xp)
+			break
+		}
+
+		return re.JSON(http.StatusOK, map[string]any{
+			"id":               user.Id,
+			"username":         user.GetString("username"),
+			"name":             user.GetString("name"),
+			"avatar_url":       user.GetString("avatar_url"),
+			"country":          user.GetString("country"),
+			"coins":            user.GetInt("coins"),
+			"level":            level,
+			"xp":               xp,
+			"level_xp":         levelXP,
+			"next_level_xp":    nextLevelXP,
+			"xp_to_next_level": xpToNextLevel,
+			"daily_spins":      dailySpins + user.GetInt("bonus_spins"),
+			"unlocks":          levelUnlocks(curve, level),
+			"recent_level_ups": recentLevelUps,
+		})
+	})
+}
+
+// registerXPHooks keeps users.level server managed and grants the referral XP.
+func registerXPHooks(app core.App) {
+	// the level follows the XP, the users can't set it themselves
+	app.OnRecordUpdateRequest("users").BindFunc(func(e *core.RecordRequestEvent) error {
+		if !e.HasSuperuserAuth() {
+			e.Record.Set("level", e.Record.Original().GetInt("level"))
+		}
+		return e.Next()
+	})
+
+	// in the same transaction as the new user
+	app.OnRecordCreateExecute("users").BindFunc(func(e *core.RecordEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		referrerId := e.Record.GetString("referred_by")
+		if referrerId == "" {
+			return nil
+		}
+
+		referrer, err := e.App.FindRecordById("users", referrerId)
+		if err != nil {
+			return err
+		}
+
+		return grantXP(e.App, referrer, xpReward(e.App, "referral"), "referral", e.Record.Id)
+	})
+}
+
+// xpReward returns the XP a source grants, xp_rewards_config overriding the defaults.
+func xpReward(app core.App, source string) int {
+	config, err := app.FindFirstRecordByData("xp_rewards_config", "source", source)
+	if err != nil {
+		return defaultXPRewards[source]
+	}
+
+	return config.GetInt("xp")
+}
+
+// loadLevelCurve returns the level_curve steps by level.
+func loadLevelCurve(app core.App) ([]levelStep, error) {
+	records, err := app.FindRecordsByFilter("level_curve", "1=1", "level", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := make([]levelStep, 0, len(records))
+	for _, r := range records {
+		step := levelStep{
+			Level:       r.GetInt("level"),
+			XPRequired:  r.GetInt("xp_required"),
+			RewardCoins: r.GetInt("reward_coins"),
+			RewardSpins: r.GetInt("reward_spins"),
+			Unlocks:     []string{},
+		}
+		_ = r.UnmarshalJSONField("unlocks", &step.Unlocks)
+		curve = append(curve, step)
+	}
+
+	return curve, nil
+}
+
+// levelForXP returns the highest level of the curve reached with xp (1 for none).
+func levelForXP(curve []levelStep, xp int) int {
+	level := 1
+	for _, step := range curve {
+		if xp >= step.XPRequired {
+			level = max(level, step.Level)
+		}
+	}
+	return level
+}
+
+// levelUnlocks returns the unlocks of every level up to level.
+func levelUnlocks(curve []levelStep, level int) []string {
+	unlocks := []string{}
+	for _, step := range curve {
+		if step.Level > level {
+			break
+		}
+		for _, unlock := range step.Unlocks {
+			if !slices.Contains(unlocks, unlock) {
+				unlocks = append(unlocks, unlock)
+			}
+		}
+	}
+	return unlocks
+}
+
+// grantXP adds amount XP to the user and records it in the xp_events
+// ledger. Every level reached with it is recorded in level_ups and its
+// rewards given (coins, extra daily spins), with a notification.
+// It all happens in one transaction, with the other pending changes of user.
+func grantXP(app core.App, user *core.Record, amount int, source string, ref string) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	return app.RunInTransaction(func(txApp core.App) error {
+		eventsCollection, err := txApp.FindCachedCollectionByNameOrId("xp_events")
+		if err != nil {
+			return err
+		}
+
+		event := core.NewRecord(eventsCollection)
+		event.Set("user", user.Id)
+		event.Set("amount", amount)
+		event.Set("source", source)
+		event.Set("ref", ref)
+		if err := txApp.Save(event); err != nil {
+			return err
+		}
+
+		curve, err := loadLevelCurve(txApp)
+		if err != nil {
+			return err
+		}
+
+		xp := user.GetInt("xp") + amount
+		oldLevel := user.GetInt("level")
+		newLevel := max(oldLevel, levelForXP(curve, xp))
+
+		user.Set("xp", xp)
+		user.Set("level", newLevel)
+
+		if newLevel == oldLevel {
+			return txApp.Save(user)
+		}
+
+		now := time.Now().UTC()
+		levelUpsCollection, err := txApp.FindCachedCollectionByNameOrId("level_ups")
+		if err != nil {
+			return err
+		}
+		notificationsCollection, err := txApp.FindCachedCollectionByNameOrId("notifications")
+		if err != nil {
+			return err
+		}
+
+		for _, step := range curve {
+			if step.Level <= oldLevel || step.Level > newLevel {
+				continue
+			}
+
+			// every level is rewarded once, even if the user went down since
+			reached, _ := txApp.FindFirstRecordByFilter(
+				"level_ups",
+				"user = {:user} && level = {:level}",
+				dbx.Params{"user": user.Id, "level": step.Level},
+			)
+			if reached != nil {
+				continue
+			}
+
+			levelUp := core.NewRecord(levelUpsCollection)
+			levelUp.Set("user", user.Id)
+			levelUp.Set("level", step.Level)
+			levelUp.Set("reward_coins", step.RewardCoins)
+			levelUp.Set("reward_spins", step.RewardSpins)
+			levelUp.Set("unlocks", step.Unlocks)
+			if err := txApp.Save(levelUp); err != nil {
+				return err
+			}
+
+			if step.RewardSpins > 0 {
+				user.Set("bonus_spins", user.GetInt("bonus_spins")+step.RewardSpins)
+				// today's counter is already set, the next days start with the bonus
+				if user.GetDateTime("last_spin_date").Time().UTC().Format("2006-01-02") == now.Format("2006-01-02") {
+					user.Set("daily_spins_left", user.GetInt("daily_spins_left")+step.RewardSpins)
+				}
+			}
+
+			if err := creditCoins(txApp, user, step.RewardCoins, "level_up", levelUp.Id); err != nil {
+				return err
+			}
+
+			notification := core.NewRecord(notificationsCollection)
+			notification.Set("user", user.Id)
+			notification.Set("type", "level_up")
+			notification.Set("data", map[string]any{
+				"level":        step.Level,
+				"reward_coins": step.RewardCoins,
+				"reward_spins": step.RewardSpins,
+				"unlocks":      step.Unlocks,
+			})
+			if err := txApp.Save(notification); err != nil {
+				return err
+			}
+		}
+
+		if err := txApp.Save(user); err != nil {
+			return err
+		}
+
+		log.Printf("User %s reached level %d", user.Id, newLevel)
+
+		return nil
+	})
+}
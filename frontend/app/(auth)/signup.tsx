@@ -69,6 +69,7 @@ type SignupForm = {
   username: string;
   email: string;
   password: string;
+  referrerCode?: string;
 };
 
 export default function SignupScreen() {
@@ -88,6 +89,7 @@ export default function SignupScreen() {
     username: z.string().min(3, { message: t("auth.errors.usernameMin") }),
     email: z.string().email({ message: t("auth.errors.invalidEmail") }),
     password: z.string().min(6, { message: t("auth.errors.passwordMin") }),
+    referrerCode: z.string().trim().optional(),
   });
 
   const {
@@ -108,9 +110,10 @@ export default function SignupScreen() {
         password: data.password,
         passwordConfirm: data.password, // UI doesn't have confirm field, so we match automatically
         username: data.username,
-        avatar_url: `https://api.dicebear.com/9.x/avataaars/png?seed=${data.username}&backgroundColor=b6e3f4`,
         coins: 0,
         daily_spins_left: 3,
+        // makes the referrer and the new user friends
+        referrer_code: data.referrerCode || undefined,
       });
 
       Alert.alert(
@@ -143,6 +146,9 @@ export default function SignupScreen() {
         if (apiErrors.password) {
           setError("password", { message: apiErrors.password.message });
         }
+        if (apiErrors.code === "invalid_referral_code") {
+          setError("referrerCode", { message: error.message });
+        }
       } else {
         // Fallback generic error
         const msg = error.message || t("auth.signupFailed");
@@ -329,6 +335,56 @@ export default function SignupScreen() {
                 )}
               </View>
 
+              {/* Referral Code Input (optional) */}
+              <View style={styles.inputWrapper}>
+                <Text style={styles.label}>
+                  {t("auth.referralCodeLabel", "Referral code (optional)")}
+                </Text>
+                <Controller
+                  control={control}
+                  name="referrerCode"
+                  render={({ field: { onChange, value } }) => (
+                    <BlurView
+                      intensity={Platform.OS === "web" ? 0 : 30}
+                      tint="dark"
+                      style={[
+                        styles.blurContainer,
+                        focusedField === "referrerCode" && styles.blurFocused,
+                        errors.referrerCode && styles.blurError,
+                        Platform.OS === "web" && styles.webInputBackground,
+                      ]}
+                    >
+                      <Ionicons
+                        name="gift-outline"
+                        size={20}
+                        color={COLORS.textPlaceholder}
+                        style={styles.inputIcon}
+                      />
+                      <TextInput
+                        style={styles.input}
+                        placeholder={t(
+                          "auth.referralCodePlaceholder",
+                          "Friend's referral code",
+                        )}
+                        placeholderTextColor={COLORS.textPlaceholder}
+                        autoCapitalize="characters"
+                        value={value}
+                        onChangeText={onChange}
+                        onFocus={() => setFocusedField("referrerCode")}
+                        onBlur={() => setFocusedField(null)}
+                        cursorColor={COLORS.accentCyan}
+                        selectionColor={COLORS.accentCyan}
+                      />
+                    </BlurView>
+                  )}
+                />
+                {errors.referrerCode && (
+                  <Text style={styles.errorText}>
+                    {errors.referrerCode.message}
+                  </Text>
+                )}
+              </View>
+
               {/* Gradient Action Button */}
               <TouchableOpacity
                 onPress={handleSubmit(onSubmit)}
@@ -22,6 +22,7 @@ import { useInterstitialAd } from "@/hooks/ads/useInterstitialAd";
 import { getAdUnitId } from "@/utils/adsConfig";
 
 import { useTheme } from "@/context/ThemeContext";
+import { useFavorites } from "@/hooks/useFavorites";
 import { Theme } from "@/types";
 import { FEATURED_GAMES } from "@/data/dummyData";
 import Colors from "@/constants/Colors";
@@ -33,6 +34,7 @@ export default function GameDetailsScreen() {
   const router = useRouter();
   const theme = useTheme();
   const params = useLocalSearchParams();
+  const { isFavorite, toggleFavorite } = useFavorites();
 
   // 1. RESPONSIVE DIMENSIONS
   const { width: windowWidth, height: windowHeight } = useWindowDimensions();
@@ -68,6 +70,7 @@ export default function GameDetailsScreen() {
     router.push({
       pathname: "/game-player",
       params: {
+        id: game.id,
         url: game.url,
         title: game.title,
         orientation: game.orientation,
@@ -89,7 +92,7 @@ export default function GameDetailsScreen() {
     title: params.title as string,
     image: params.image as string,
     category: (params.category as string) || "Arcade",
-    rating: params.rating || "4.5",
+    rating: params.rating || "-",
     description: (params.description as string) || "Experience the thrill...",
     url: params.url as string,
     orientation: params.orientation as string,
@@ -131,6 +134,20 @@ export default function GameDetailsScreen() {
               >
                 <Ionicons name="arrow-back" size={24} color="#fff" />
               </TouchableOpacity>
+              <TouchableOpacity
+                style={styles.backButton}
+                onPress={() => toggleFavorite(game.id as string)}
+              >
+                <Ionicons
+                  name={
+                    isFavorite(game.id as string) ? "heart" : "heart-outline"
+                  }
+                  size={24}
+                  color={
+                    isFavorite(game.id as string) ? theme.primary : "#fff"
+                  }
+                />
+              </TouchableOpacity>
             </SafeAreaView>
           </View>
 
@@ -272,6 +289,9 @@ const createStyles = (
       position: "absolute",
       top: 0,
       left: 0,
+      right: 0,
+      flexDirection: "row",
+      justifyContent: "space-between",
       padding: 20,
       zIndex: 10,
     },
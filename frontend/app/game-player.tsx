@@ -15,15 +15,50 @@ import * as ScreenOrientation from "expo-screen-orientation";
 import { Stack, useLocalSearchParams, useRouter } from "expo-router";
 import { Ionicons } from "@expo/vector-icons";
 import { SafeAreaView } from "react-native-safe-area-context";
+import { useGameSession } from "@/hooks/useGameSession";
+import { pb } from "@/utils/pocketbase";
 
 export default function GamePlayerScreen() {
   const router = useRouter();
-  const { url, title, orientation } = useLocalSearchParams();
+  const { id, url, title, orientation } = useLocalSearchParams();
   const [isLoading, setIsLoading] = useState(true);
   const webViewRef = useRef<WebView>(null);
 
   const isWeb = Platform.OS === "web";
 
+  // Play-to-earn: the server tracks the session and pays out when it ends
+  const { sessionId, scoreKey, submitBridgeMessage } = useGameSession(
+    id as string | undefined,
+  );
+  const [bridgeScript, setBridgeScript] = useState<string | null>(null);
+
+  // Score bridge: fetched once from the backend and injected into the game page
+  useEffect(() => {
+    if (isWeb) return; // cross-origin iframes can't be injected
+    fetch(pb.buildURL("/api/score-bridge.js"))
+      .then((res) => (res.ok ? res.text() : null))
+      .then(setBridgeScript)
+      .catch((error) => console.warn("Score bridge unavailable:", error));
+  }, []);
+
+  const bridgeInjection =
+    bridgeScript && sessionId && scoreKey
+      ? `window.__MYSTERYPLAY__ = ${JSON.stringify({ sessionId, key: scoreKey })};
+${bridgeScript}
+true;`
+      : null;
+
+  const injectBridge = () => {
+    if (bridgeInjection) {
+      webViewRef.current?.injectJavaScript(bridgeInjection);
+    }
+  };
+
+  // The session may start after the page has loaded
+  useEffect(() => {
+    if (!isLoading) injectBridge();
+  }, [bridgeInjection]);
+
   useEffect(() => {
     // 1. ORIENTATION & STATUS BAR (Native Only)
     if (!isWeb) {
@@ -98,7 +133,11 @@ export default function GamePlayerScreen() {
         ref={webViewRef}
         source={{ uri: url as string }}
         onLoadStart={() => setIsLoading(true)}
-        onLoadEnd={() => setIsLoading(false)}
+        onLoadEnd={() => {
+          setIsLoading(false);
+          injectBridge();
+        }}
+        onMessage={(event) => submitBridgeMessage(event.nativeEvent.data)}
         javaScriptEnabled={true}
         startInLoadingState={false}
         scalesPageToFit={true}
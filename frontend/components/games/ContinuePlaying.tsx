@@ -25,6 +25,7 @@ const RecentGameCard: React.FC<{ game: Game; theme: Theme; styles: any }> = ({
   const gameLink: Href = {
     pathname: "/game-player",
     params: {
+      id: game.id,
       url: game.url,
       title: game.title,
       orientation: game.orientation,
@@ -70,9 +71,14 @@ const RecentGameCard: React.FC<{ game: Game; theme: Theme; styles: any }> = ({
 // --- MAIN COMPONENT ---
 type ContinuePlayingProps = {
   data: Game[];
+  // section title, defaults to "Continue Playing"
+  title?: string;
 };
 
-export const ContinuePlaying: React.FC<ContinuePlayingProps> = ({ data }) => {
+export const ContinuePlaying: React.FC<ContinuePlayingProps> = ({
+  data,
+  title,
+}) => {
   const theme = useTheme();
   const { t } = useTranslation();
   const styles = useMemo(() => createStyles(theme), [theme]);
@@ -84,7 +90,7 @@ export const ContinuePlaying: React.FC<ContinuePlayingProps> = ({ data }) => {
   return (
     <View style={styles.sectionContainer}>
       <Text style={styles.title}>
-        {t("home.continuePlaying", "Continue Playing")}
+        {title ?? t("home.continuePlaying", "Continue Playing")}
       </Text>
 
       <FlatList
@@ -14,6 +14,8 @@ import { GameCategories as GameCategoriesType } from "@/types";
 
 type GameCategoriesProps = {
   categories: string[];
+  // Display names by slug (from /api/categories), falls back to the translations
+  labels?: Record<string, string>;
   onSelectCategory: (category: GameCategoriesType) => void;
 };
 
@@ -36,6 +38,23 @@ const getCategoryData = (category: string) => {
       return { icon: "soccer", color: "#2af598" };
     case "adventure":
       return { icon: "compass", color: "#F4D03F" };
+    case "cards":
+      return { icon: "cards-playing", color: "#e52d27" };
+    case "match-3":
+      return { icon: "diamond-stone", color: "#00c6ff" };
+    case "mahjong":
+      return { icon: "mahjong", color: "#d38312" };
+    case "board":
+      return { icon: "checkerboard", color: "#a8e063" };
+    // catalog sorts
+    case "new":
+      return { icon: "new-box", color: "#4facfe" };
+    case "trending":
+      return { icon: "fire", color: "#ff4b1f" };
+    case "popular":
+      return { icon: "account-group", color: "#f7971e" };
+    case "rating":
+      return { icon: "star", color: "#FFD700" };
     default:
       return { icon: "gamepad-variant", color: "#bdc3c7" };
   }
@@ -110,6 +129,7 @@ const CategoryChip = ({
 
 export const GameCategories: React.FC<GameCategoriesProps> = ({
   categories,
+  labels,
   onSelectCategory,
 }) => {
   const { t } = useTranslation(); // 3. Init translation
@@ -131,9 +151,12 @@ export const GameCategories: React.FC<GameCategoriesProps> = ({
             item={item}
             // 4. Translate here. We convert key to lowercase to match JSON keys.
             // Example: "Action" -> t("categories.action") -> "Action" (or translated)
-            label={t(`categories.${item.toLowerCase()}`, {
-              defaultValue: item,
-            })}
+            label={
+              labels?.[item] ??
+              t(`categories.${item.toLowerCase()}`, {
+                defaultValue: item,
+              })
+            }
             isActive={item === activeCategory}
             onPress={() => handlePress(item)}
             styles={styles}
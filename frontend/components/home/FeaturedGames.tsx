@@ -21,7 +21,7 @@ import Animated, {
 import { useTheme } from "@/context/ThemeContext";
 import { Game, Theme } from "@/types";
 import { GameCategories } from "@/components/games/GameCategories";
-import { GAME_CATEGORIES } from "@/data/dummyData";
+import { useCategories } from "@/hooks/useCategories";
 import { useFeaturedGames } from "@/hooks/useFeaturedGames";
 import { useInterstitialAd } from "@/hooks/ads/useInterstitialAd";
 
@@ -93,13 +93,31 @@ const FeaturedGameCard = ({
   );
 };
 
-export const FeaturedGames: React.FC = () => {
+type FeaturedGamesProps = {
+  // already loaded games (eg. from /api/home), fetched here otherwise
+  data?: Game[];
+};
+
+export const FeaturedGames: React.FC<FeaturedGamesProps> = ({ data }) => {
   const { t } = useTranslation();
   const theme = useTheme();
-  const { games, loading } = useFeaturedGames();
+  const fetched = useFeaturedGames(data === undefined);
+  const games = data ?? fetched.games;
+  const loading = data === undefined && fetched.loading;
   console.log("games;", games);
   const [selectedCategory, setSelectedCategory] = useState("All");
 
+  // Categories are managed on the server (game_categories collection)
+  const { categories } = useCategories();
+  const categoryList = useMemo(
+    () => ["All", ...categories.map((c) => c.slug)],
+    [categories],
+  );
+  const categoryLabels = useMemo(
+    () => Object.fromEntries(categories.map((c) => [c.slug, c.name])),
+    [categories],
+  );
+
   // --- 2. RESPONSIVE LAYOUT LOGIC ---
   const { width: windowWidth } = useWindowDimensions();
   const isDesktop = windowWidth > 768;
@@ -154,7 +172,8 @@ export const FeaturedGames: React.FC = () => {
     <View style={styles.section}>
       <Text style={styles.sectionTitle}>{t("home.featuredGames")}</Text>
       <GameCategories
-        categories={GAME_CATEGORIES}
+        categories={categoryList}
+        labels={categoryLabels}
         selectedCategory={selectedCategory}
         onSelectCategory={handleCategorySelect}
       />
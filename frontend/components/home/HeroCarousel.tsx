@@ -1,4 +1,4 @@
-import React from "react";
+import React, { useEffect } from "react";
 import {
   View,
   Text,
@@ -14,6 +14,7 @@ import { Ionicons } from "@expo/vector-icons";
 
 import { useTheme } from "@/context/ThemeContext";
 import { HeroBannerItem } from "@/types";
+import { trackBannerEvent } from "@/utils/bannerTracking";
 
 type HeroCarouselProps = {
   data: HeroBannerItem[];
@@ -35,13 +36,24 @@ export const HeroCarousel: React.FC<HeroCarouselProps> = ({ data }) => {
   const carouselWidth = Math.min(windowWidth, 1024); // Cap at container width
   const itemHeight = isDesktop ? DESKTOP_ITEM_HEIGHT : MOBILE_ITEM_HEIGHT;
 
+  // the first banner is visible right away, the others once swiped to
+  useEffect(() => {
+    if (data?.length) {
+      trackBannerEvent(data[0].id, "impression");
+    }
+  }, [data]);
+
   if (!data || data.length === 0) {
     return null;
   }
 
   const renderCarouselItem = ({ item }: { item: HeroBannerItem }) => (
     <Link href={item.href} asChild>
-      <TouchableOpacity activeOpacity={0.9} style={styles.cardContainer}>
+      <TouchableOpacity
+        activeOpacity={0.9}
+        style={styles.cardContainer}
+        onPress={() => trackBannerEvent(item.id, "click")}
+      >
         <Image
           source={{ uri: item.image }}
           style={styles.image}
@@ -97,6 +109,9 @@ export const HeroCarousel: React.FC<HeroCarouselProps> = ({ data }) => {
           parallaxScrollingOffset: 50,
         }}
         renderItem={renderCarouselItem}
+        onSnapToItem={(index) =>
+          trackBannerEvent(data[index]?.id, "impression")
+        }
       />
     </View>
   );
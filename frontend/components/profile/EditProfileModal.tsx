@@ -13,6 +13,7 @@ import {
   KeyboardAvoidingView,
   useWindowDimensions,
   ScrollView,
+  Switch,
 } from "react-native";
 import { Ionicons } from "@expo/vector-icons";
 import * as ImagePicker from "expo-image-picker";
@@ -48,6 +49,12 @@ export const EditProfileModal: React.FC<EditProfileModalProps> = ({
     username: "",
     avatarUri: "",
     hasNewAvatar: false,
+    hideName: false,
+    hideLevel: false,
+    hideAchievements: false,
+    hideJoinDate: false,
+    shareFavorites: false,
+    country: "",
   });
 
   useEffect(() => {
@@ -57,6 +64,12 @@ export const EditProfileModal: React.FC<EditProfileModalProps> = ({
         username: currentUser.username || "",
         avatarUri: currentUser.avatar || "",
         hasNewAvatar: false,
+        hideName: currentUser.hideName || false,
+        hideLevel: currentUser.hideLevel || false,
+        hideAchievements: currentUser.hideAchievements || false,
+        hideJoinDate: currentUser.hideJoinDate || false,
+        shareFavorites: currentUser.shareFavorites || false,
+        country: currentUser.country || "",
       });
     }
   }, [visible, currentUser]);
@@ -105,7 +118,18 @@ export const EditProfileModal: React.FC<EditProfileModalProps> = ({
 
       data.append("name", formData.name);
       data.append("username", formData.username);
+      data.append("hide_name", String(formData.hideName));
+      data.append("hide_level", String(formData.hideLevel));
+      data.append("hide_achievements", String(formData.hideAchievements));
+      data.append("hide_join_date", String(formData.hideJoinDate));
+      data.append("share_favorites", String(formData.shareFavorites));
+      data.append("country", formData.country.trim().toUpperCase());
 
+      await pb.collection("users").update(currentUser.id, data);
+
+      // The avatar goes through the moderation queue (the server crops and
+      // resizes it), it only shows once approved
+      let avatarPending = false;
       if (formData.hasNewAvatar) {
         // Create the file object for the FormData
         const uri = formData.avatarUri;
@@ -113,24 +137,34 @@ export const EditProfileModal: React.FC<EditProfileModalProps> = ({
         const match = /\.(\w+)$/.exec(name || "");
         const type = match ? `image/${match[1]}` : `image`;
 
+        const avatarData = new FormData();
         // @ts-ignore - React Native FormData requires this structure
-        data.append("avatar", {
+        avatarData.append("avatar", {
           uri: uri,
           name: name,
           type: type,
         });
-      }
 
-      // Change 4: Single update call handles everything
-      await pb.collection("users").update(currentUser.id, data);
+        const result = await pb.send("/api/me/avatar", {
+          method: "POST",
+          body: avatarData,
+        });
+        avatarPending = result.upload?.status === "pending";
+      }
 
-      Alert.alert("Success", "Profile updated successfully!");
+      Alert.alert(
+        "Success",
+        avatarPending
+          ? "Profile updated! Your new avatar will show once it's approved."
+          : "Profile updated successfully!",
+      );
       onProfileUpdate();
       onClose();
     } catch (error: any) {
       // PocketBase validation errors (e.g. username taken)
       const message =
         error.data?.data?.username?.message ||
+        error.data?.data?.country?.message ||
         error.message ||
         "An error occurred";
 
@@ -227,6 +261,86 @@ export const EditProfileModal: React.FC<EditProfileModalProps> = ({
                   />
                 </View>
 
+                <View style={styles.inputGroup}>
+                  <Text style={styles.inputLabel}>Country</Text>
+                  <TextInput
+                    style={styles.inputField}
+                    value={formData.country}
+                    onChangeText={(text) =>
+                      setFormData((prev) => ({ ...prev, country: text }))
+                    }
+                    placeholder="Country code, e.g. DE"
+                    placeholderTextColor={theme.textTertiary}
+                    autoCapitalize="characters"
+                    maxLength={2}
+                  />
+                </View>
+
+                <View style={[styles.inputGroup, styles.switchRow]}>
+                  <Text style={styles.inputLabel}>
+                    Show display name on leaderboards
+                  </Text>
+                  <Switch
+                    value={!formData.hideName}
+                    onValueChange={(value) =>
+                      setFormData((prev) => ({ ...prev, hideName: !value }))
+                    }
+                    trackColor={{ true: theme.primary }}
+                  />
+                </View>
+
+                {/* Public profile sections */}
+                <View style={[styles.inputGroup, styles.switchRow]}>
+                  <Text style={styles.inputLabel}>Show level on profile</Text>
+                  <Switch
+                    value={!formData.hideLevel}
+                    onValueChange={(value) =>
+                      setFormData((prev) => ({ ...prev, hideLevel: !value }))
+                    }
+                    trackColor={{ true: theme.primary }}
+                  />
+                </View>
+
+                <View style={[styles.inputGroup, styles.switchRow]}>
+                  <Text style={styles.inputLabel}>
+                    Show achievements on profile
+                  </Text>
+                  <Switch
+                    value={!formData.hideAchievements}
+                    onValueChange={(value) =>
+                      setFormData((prev) => ({
+                        ...prev,
+                        hideAchievements: !value,
+                      }))
+                    }
+                    trackColor={{ true: theme.primary }}
+                  />
+                </View>
+
+                <View style={[styles.inputGroup, styles.switchRow]}>
+                  <Text style={styles.inputLabel}>
+                    Show join date on profile
+                  </Text>
+                  <Switch
+                    value={!formData.hideJoinDate}
+                    onValueChange={(value) =>
+                      setFormData((prev) => ({ ...prev, hideJoinDate: !value }))
+                    }
+                    trackColor={{ true: theme.primary }}
+                  />
+                </View>
+
+                <View style={[styles.inputGroup, styles.switchRow]}>
+                  <Text style={styles.inputLabel}>Share favorite games</Text>
+                  <Switch
+                    value={formData.shareFavorites}
+                    onValueChange={(value) =>
+                      setFormData((prev) => ({ ...prev, shareFavorites: value }))
+                    }
+                    trackColor={{ true: theme.primary }}
+                  />
+                </View>
+
                 {/* Save Button */}
                 <TouchableOpacity
                   style={styles.saveButton}
@@ -326,6 +440,11 @@ const createStyles = (theme: Theme, isDesktop: boolean) =>
       width: "100%",
       marginBottom: 20,
     },
+    switchRow: {
+      flexDirection: "row",
+      alignItems: "center",
+      justifyContent: "space-between",
+    },
     inputLabel: {
       color: theme.textSecondary,
       fontSize: 12,
@@ -0,0 +1,36 @@
+import { useState, useEffect, useCallback } from "react";
+import { useTranslation } from "react-i18next";
+import { pb } from "@/utils/pocketbase";
+import { GameCategory } from "@/types";
+
+/**
+ * Loads the visible game categories (managed in the `game_categories`
+ * collection) with their names in the current app language.
+ */
+export const useCategories = () => {
+  const { i18n } = useTranslation();
+  const [categories, setCategories] = useState<GameCategory[]>([]);
+  const [loading, setLoading] = useState(true);
+
+  const fetchCategories = useCallback(async () => {
+    try {
+      setLoading(true);
+      const data = await pb.send("/api/categories", {
+        query: { lang: i18n.language },
+      });
+      setCategories(data.categories);
+    } catch (error: any) {
+      if (!error.isAbort) {
+        console.error("Error fetching categories:", error);
+      }
+    } finally {
+      setLoading(false);
+    }
+  }, [i18n.language]);
+
+  useEffect(() => {
+    fetchCategories();
+  }, [fetchCategories]);
+
+  return { categories, loading, refetch: fetchCategories };
+};
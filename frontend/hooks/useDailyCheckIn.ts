@@ -2,6 +2,7 @@ import { useState, useEffect, useCallback } from "react";
 import { pb, persistAuth } from "@/utils/pocketbase";
 import { Alert } from "react-native";
 import { useUserStats } from "@/context/UserStatsContext";
+import { apiMessage } from "@/utils/apiMessages";
 
 export type DailyRewardItem = {
   day: number;
@@ -102,7 +103,7 @@ export const useDailyCheckIn = () => {
       });
 
       if (!response.success) {
-        Alert.alert("Info", response.message);
+        Alert.alert("Info", apiMessage(response, "Already claimed today!"));
         return false;
       }
 
@@ -124,7 +125,7 @@ export const useDailyCheckIn = () => {
       return response.reward || 0;
     } catch (err: any) {
       console.error("Claim Exception:", err);
-      Alert.alert("Error", err.message || "Failed to claim.");
+      Alert.alert("Error", apiMessage(err, "Failed to claim."));
       return false;
     }
   };
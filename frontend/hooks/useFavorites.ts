@@ -0,0 +1,71 @@
+import { useState, useEffect, useCallback } from "react";
+import { pb } from "@/utils/pocketbase";
+
+/**
+ * Keeps the ids of the current user's favorite games and toggles them
+ * through the favorites routes.
+ */
+export const useFavorites = () => {
+  const [favoriteIds, setFavoriteIds] = useState<Set<string>>(new Set());
+  const [loading, setLoading] = useState(true);
+
+  const fetchFavorites = useCallback(async () => {
+    if (!pb.authStore.isValid) {
+      setLoading(false);
+      return;
+    }
+
+    try {
+      setLoading(true);
+      const data = await pb.send("/api/me/favorites", {
+        query: { perPage: 100 },
+      });
+      setFavoriteIds(new Set(data.items.map((game: any) => game.id)));
+    } catch (error: any) {
+      if (!error.isAbort) {
+        console.error("Error fetching favorites:", error);
+      }
+    } finally {
+      setLoading(false);
+    }
+  }, []);
+
+  const toggleFavorite = useCallback(
+    async (gameId: string) => {
+      const isFavorite = favoriteIds.has(gameId);
+
+      // optimistic update, reverted when the request fails
+      setFavoriteIds((prev) => {
+        const next = new Set(prev);
+        isFavorite ? next.delete(gameId) : next.add(gameId);
+        return next;
+      });
+
+      try {
+        await pb.send(`/api/games/${gameId}/favorite`, {
+          method: isFavorite ? "DELETE" : "POST",
+        });
+      } catch (error) {
+        console.error("Error updating favorite:", error);
+        setFavoriteIds((prev) => {
+          const next = new Set(prev);
+          isFavorite ? next.add(gameId) : next.delete(gameId);
+          return next;
+        });
+      }
+    },
+    [favoriteIds],
+  );
+
+  useEffect(() => {
+    fetchFavorites();
+  }, [fetchFavorites]);
+
+  return {
+    favoriteIds,
+    isFavorite: (gameId: string) => favoriteIds.has(gameId),
+    toggleFavorite,
+    loading,
+    refetch: fetchFavorites,
+  };
+};
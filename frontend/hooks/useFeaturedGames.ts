@@ -1,51 +1,36 @@
 import { useState, useEffect, useCallback } from "react";
 import { pb } from "@/utils/pocketbase";
 import { Game } from "@/types";
-import { getStorageUrl } from "@/utils/imageHelpers";
+import { getGameImageUrl } from "@/utils/imageHelpers";
 
-export const useFeaturedGames = () => {
+// enabled = false when the games come from /api/home
+export const useFeaturedGames = (enabled = true) => {
   const [games, setGames] = useState<Game[]>([]);
   const [loading, setLoading] = useState(true);
 
   const fetchFeatured = useCallback(async () => {
+    if (!enabled) {
+      setLoading(false);
+      return;
+    }
+
     try {
       setLoading(true);
 
-      const records = await pb.collection("featured_games").getFullList({
-        filter: "is_active = true",
-        sort: "display_order",
-        // 1. FIXED: Changed "game" to "game_id" to match your schema
-        expand: "game_id",
-      });
-
-      console.log("Raw records from PB:", records);
-
-      // 2. Map the data using the correct expand key
-      const formattedGames = records
-        .map((item) => {
-          // Use the correct field name here as well
-          const gameRecord = item.expand?.game_id;
-
-          if (!gameRecord) {
-            console.warn(
-              `No expanded game found for featured record: ${item.id}. Check API permissions.`,
-            );
-            return null;
-          }
-
-          const gameFormattedRecord: Game = {
-            id: gameRecord.id,
-            title: gameRecord.title,
-            image: getStorageUrl(gameRecord, gameRecord.image),
-            category: gameRecord.category,
-            url: gameRecord.url,
-            orientation: gameRecord.orientation,
-            description: gameRecord.description,
-          };
-
-          return gameFormattedRecord;
-        })
-        .filter((g): g is Game => g !== null);
+      // curated featured games, topped up with the most favorited ones
+      const data = await pb.send("/api/games/featured", {});
+
+      const formattedGames: Game[] = data.items.map((gameRecord: any) => ({
+        id: gameRecord.id,
+        title: gameRecord.title,
+        image: getGameImageUrl(gameRecord),
+        category: gameRecord.expand?.category?.slug,
+        url: gameRecord.url,
+        orientation: gameRecord.orientation,
+        description: gameRecord.description,
+        rating_avg: gameRecord.rating_avg,
+        rating_count: gameRecord.rating_count,
+      }));
 
       setGames(formattedGames);
     } catch (error) {
@@ -53,7 +38,7 @@ export const useFeaturedGames = () => {
     } finally {
       setLoading(false);
     }
-  }, []);
+  }, [enabled]);
 
   useEffect(() => {
     fetchFeatured();
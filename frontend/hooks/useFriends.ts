@@ -0,0 +1,95 @@
+import { useState, useEffect, useCallback } from "react";
+import { pb } from "@/utils/pocketbase";
+import { Friendship, FriendUser } from "@/types";
+import { getStorageUrl } from "@/utils/imageHelpers";
+
+// the avatar urls can be relative to the backend
+const withAvatarUrl = (user: FriendUser): FriendUser => ({
+  ...user,
+  avatar_url: getStorageUrl(user, user.avatar_url),
+});
+
+const withFriendAvatarUrl = (friendship: Friendship): Friendship => ({
+  ...friendship,
+  user: withAvatarUrl(friendship.user),
+});
+
+/**
+ * The user's friends and pending friend requests, with the actions to
+ * send (by user id or referral code), accept and remove them.
+ */
+export const useFriends = () => {
+  const [loading, setLoading] = useState(true);
+  const [friends, setFriends] = useState<Friendship[]>([]);
+  const [incoming, setIncoming] = useState<Friendship[]>([]);
+  const [outgoing, setOutgoing] = useState<Friendship[]>([]);
+
+  const fetchFriends = useCallback(async () => {
+    try {
+      setLoading(true);
+      const data = await pb.send("/api/friends", { method: "GET" });
+      setFriends(data.friends.map(withFriendAvatarUrl));
+      setIncoming(data.incoming.map(withFriendAvatarUrl));
+      setOutgoing(data.outgoing.map(withFriendAvatarUrl));
+    } catch (err) {
+      console.error("Error fetching friends:", err);
+    } finally {
+      setLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    fetchFriends();
+  }, [fetchFriends]);
+
+  const searchPlayers = useCallback(
+    async (q: string): Promise<FriendUser[]> => {
+      const data = await pb.send("/api/friends/search", {
+        method: "GET",
+        query: { q },
+      });
+      return data.items.map(withAvatarUrl);
+    },
+    [],
+  );
+
+  const sendRequest = useCallback(
+    async (target: { user?: string; referral_code?: string }) => {
+      await pb.send("/api/friends/requests", {
+        method: "POST",
+        body: target,
+      });
+      await fetchFriends();
+    },
+    [fetchFriends],
+  );
+
+  const acceptRequest = useCallback(
+    async (id: string) => {
+      await pb.send(`/api/friends/requests/${id}/accept`, { method: "POST" });
+      await fetchFriends();
+    },
+    [fetchFriends],
+  );
+
+  // declines, cancels or unfriends
+  const removeFriend = useCallback(
+    async (id: string) => {
+      await pb.send(`/api/friends/${id}`, { method: "DELETE" });
+      await fetchFriends();
+    },
+    [fetchFriends],
+  );
+
+  return {
+    friends,
+    incoming,
+    outgoing,
+    loading,
+    searchPlayers,
+    sendRequest,
+    acceptRequest,
+    removeFriend,
+    refetch: fetchFriends,
+  };
+};
@@ -1,7 +1,8 @@
 import { useState, useEffect, useCallback } from "react";
 import { pb } from "@/utils/pocketbase";
-import { Game, GameCategories } from "@/types";
-import { getStorageUrl } from "@/utils/imageHelpers";
+import { Game, GameCategories, GameSort } from "@/types";
+import { getGameImageUrl } from "@/utils/imageHelpers";
+import i18n from "@/i18n";
 
 const PAGE_SIZE = 10;
 
@@ -13,6 +14,8 @@ export const useGameCatalog = () => {
   const [selectedCategory, setSelectedCategory] = useState<
     GameCategories | "All"
   >("All");
+  const [favoritesOnly, setFavoritesOnly] = useState(false);
+  const [sortBy, setSortBy] = useState<GameSort>("new");
 
   const [page, setPage] = useState(1); // PocketBase is 1-indexed
   const [hasMore, setHasMore] = useState(true);
@@ -25,34 +28,48 @@ export const useGameCatalog = () => {
       else setLoadingMore(true);
 
       try {
-        // 1. Build Filter String
-        let filters = ["is_active = true"];
-
-        if (selectedCategory !== "All") {
-          filters.push(`category = "${selectedCategory}"`);
-        }
-
-        if (searchQuery.length > 0) {
-          // '~' is the "like/contains" operator in PocketBase
-          filters.push(`title ~ "${searchQuery}"`);
-        }
-
-        // 2. Execute Query
-        const resultList = await pb
-          .collection("games")
-          .getList(pageNumber, PAGE_SIZE, {
-            filter: filters.join(" && "),
-            sort: "-created", // PocketBase uses 'created' instead of 'created_at' by default
+        // 1. Execute Query
+        // Searches go through the full-text endpoint (prefix matching, typo
+        // tolerance, relevance ranking), browsing uses the plain collection list.
+        let resultList: { items: any[]; totalPages: number };
+
+        if (searchQuery.trim().length > 0) {
+          const data = await pb.send("/api/games/search", {
+            query: {
+              q: searchQuery,
+              category: selectedCategory !== "All" ? selectedCategory : "",
+              favorites: favoritesOnly ? "true" : "",
+              page: pageNumber,
+              perPage: PAGE_SIZE,
+              lang: i18n.language,
+            },
+          });
+          resultList = {
+            items: data.items,
+            totalPages: Math.ceil(data.totalItems / data.perPage),
+          };
+        } else {
+          // catalog route, sorted by the play stats computed on the server
+          resultList = await pb.send("/api/games", {
+            query: {
+              sort: sortBy,
+              category: selectedCategory !== "All" ? selectedCategory : "",
+              favorites: favoritesOnly ? "true" : "",
+              page: pageNumber,
+              perPage: PAGE_SIZE,
+            },
           });
+        }
 
-        // 3. Format Data
+        // 2. Format Data
         const formattedGames = resultList.items.map((game) => ({
           ...game,
           // Use the record-based image helper
-          image: getStorageUrl(game, game.image),
+          image: getGameImageUrl(game),
+          category: game.expand?.category?.slug,
         }));
 
-        // 4. Update State
+        // 3. Update State
         if (isReseting) {
           setGames(formattedGames as unknown as Game[]);
         } else {
@@ -65,7 +82,7 @@ export const useGameCatalog = () => {
           });
         }
 
-        // 5. Pagination Logic
+        // 4. Pagination Logic
         setHasMore(pageNumber < resultList.totalPages);
         setPage(pageNumber);
       } catch (error: any) {
@@ -77,14 +94,14 @@ export const useGameCatalog = () => {
         setLoadingMore(false);
       }
     },
-    [searchQuery, selectedCategory],
+    [searchQuery, selectedCategory, favoritesOnly, sortBy, i18n.language],
   );
 
   useEffect(() => {
     setPage(1);
     setHasMore(true);
     fetchGames(1);
-  }, [searchQuery, selectedCategory, fetchGames]);
+  }, [searchQuery, selectedCategory, favoritesOnly, sortBy, fetchGames]);
 
   const loadMore = () => {
     if (!loading && !loadingMore && hasMore) {
@@ -106,6 +123,10 @@ export const useGameCatalog = () => {
     setSearchQuery,
     selectedCategory,
     setSelectedCategory,
+    favoritesOnly,
+    setFavoritesOnly,
+    sortBy,
+    setSortBy,
     refresh,
     loadMore,
     hasMore,
@@ -0,0 +1,81 @@
+import { useCallback, useEffect, useRef, useState } from "react";
+import { pb } from "@/utils/pocketbase";
+import { useUserStats } from "@/context/UserStatsContext";
+
+/**
+ * Tracks a play-to-earn session on the server while the game screen is open.
+ * The server decides how much of the time counts and pays out when it ends.
+ */
+export const useGameSession = (gameId?: string) => {
+  const { refreshStats } = useUserStats();
+  const [sessionId, setSessionId] = useState<string | null>(null);
+  const [scoreKey, setScoreKey] = useState<string | null>(null);
+  const sessionRef = useRef<string | null>(null);
+
+  useEffect(() => {
+    if (!gameId || !pb.authStore.isValid) return;
+
+    let heartbeatTimer: ReturnType<typeof setInterval> | null = null;
+    let cancelled = false;
+
+    const start = async () => {
+      try {
+        const data = await pb.send(`/api/games/${gameId}/sessions`, {
+          method: "POST",
+        });
+        if (cancelled || !data.success) return;
+
+        sessionRef.current = data.session_id;
+        setSessionId(data.session_id);
+        setScoreKey(data.score_key);
+
+        heartbeatTimer = setInterval(() => {
+          pb.send(`/api/sessions/${data.session_id}/heartbeat`, {
+            method: "POST",
+          }).catch((error) => console.warn("Heartbeat failed:", error));
+        }, data.heartbeat_interval * 1000);
+      } catch (error) {
+        console.error("Could not start game session:", error);
+      }
+    };
+
+    start();
+
+    return () => {
+      cancelled = true;
+      if (heartbeatTimer) clearInterval(heartbeatTimer);
+
+      const id = sessionRef.current;
+      sessionRef.current = null;
+      if (!id) return;
+
+      pb.send(`/api/sessions/${id}/end`, { method: "POST" })
+        .then(() => refreshStats())
+        .catch((error) => console.error("Could not end game session:", error));
+    };
+  }, [gameId]);
+
+  /**
+   * Forwards a message posted by the injected score bridge
+   * (see backend/static/score-bridge.js) to the scores endpoint.
+   */
+  const submitBridgeMessage = useCallback(
+    (raw: string) => {
+      if (!gameId) return;
+      try {
+        const message = JSON.parse(raw);
+        if (message?.type !== "mysteryplay:score") return;
+
+        const { type, ...body } = message;
+        pb.send(`/api/games/${gameId}/scores`, { method: "POST", body }).catch(
+          (error) => console.warn("Score rejected:", error),
+        );
+      } catch {
+        // not one of our messages
+      }
+    },
+    [gameId],
+  );
+
+  return { sessionId, scoreKey, submitBridgeMessage };
+};
@@ -1,53 +1,76 @@
-import { useState, useEffect, useCallback } from "react";
+import { useState, useEffect, useCallback, useRef } from "react";
 import { pb } from "@/utils/pocketbase";
-import { UserProfile, HeroBannerItem, Game } from "@/types";
+import {
+  UserProfile,
+  HeroBannerItem,
+  Game,
+  HomeWallet,
+  HomeCheckIn,
+} from "@/types";
 import { Alert } from "react-native";
-import { getStorageUrl } from "@/utils/imageHelpers";
+import { getGameImageUrl, getStorageUrl } from "@/utils/imageHelpers";
+
+const toGame = (record: any): Game => ({
+  ...record,
+  image: getGameImageUrl(record),
+  category: record.expand?.category?.slug,
+});
 
 export const useHomeData = () => {
   const [loading, setLoading] = useState(true);
   const [profile, setProfile] = useState<UserProfile | null>(null);
+  const [wallet, setWallet] = useState<HomeWallet | null>(null);
   const [banners, setBanners] = useState<HeroBannerItem[]>([]);
+  const [featuredGames, setFeaturedGames] = useState<Game[]>([]);
   const [games, setGames] = useState<Game[]>([]);
+  const [checkIn, setCheckIn] = useState<HomeCheckIn | null>(null);
+  const [spinsLeft, setSpinsLeft] = useState(0);
+
+  // ETag of the last /api/home response, an unchanged home answers 304
+  const etag = useRef<string | null>(null);
 
   const fetchData = useCallback(async () => {
     try {
       setLoading(true);
 
-      // 1. Get Current User from AuthStore
+      // 1. Only for signed in users
       if (!pb.authStore.isValid || !pb.authStore.model) {
         setLoading(false);
         return;
       }
 
-      const userId = pb.authStore.model.id;
+      // 2. Everything the home screen shows in one request
+      const data = await pb.send("/api/home", {
+        headers: etag.current ? { "If-None-Match": etag.current } : {},
+        fetch: async (url: RequestInfo | URL, config?: RequestInit) => {
+          const response = await fetch(url, config);
+          etag.current = response.headers.get("ETag") ?? etag.current;
+          return response;
+        },
+      });
 
-      // 2. Fetch User Profile, Banners, and Games in parallel
-      const [profileData, bannerData, gamesData] = await Promise.all([
-        pb.collection("users").getOne(userId),
-        pb.collection("banners").getFullList({
-          filter: "is_active = true",
-        }),
-        pb.collection("games").getList(1, 5),
-      ]);
+      // 304 Not Modified (empty body), keep what we have
+      if (!data.user) return;
 
       // 3. Map Profile State
       setProfile({
-        id: profileData.id,
-        username: profileData.username,
-        name: profileData.name || profileData.username || "Player",
-        // PocketBase uses the record + filename to generate the URL
-        avatar_url: getStorageUrl(profileData, profileData.avatar_url),
-        coins: profileData.coins,
-        joinDate: profileData.created,
-        level: profileData.level,
-        referralCode: profileData.referral_code,
+        id: data.user.id,
+        username: data.user.username,
+        name: data.user.name || data.user.username || "Player",
+        avatar_url: getStorageUrl(data.user, data.user.avatar_url),
+        coins: data.wallet.coins,
+        joinDate: data.user.created,
+        level: data.user.level,
+        referralCode: data.user.referral_code,
       } as unknown as UserProfile);
 
-      // 4. Map Banners and Games
-      // Note: If banners/games have images, you'd apply getStorageUrl to them too
-      setBanners(bannerData as unknown as HeroBannerItem[]);
-      setGames(gamesData.items as unknown as Game[]);
+      // 4. Map the home sections
+      setWallet(data.wallet);
+      setBanners(data.banners as HeroBannerItem[]);
+      setFeaturedGames(data.featured_games.map(toGame));
+      setGames(data.continue_playing.map(toGame));
+      setCheckIn(data.check_in);
+      setSpinsLeft(data.spins_left);
     } catch (error: any) {
       console.error("Error fetching home data:", error);
       // Only alert if it's not a cancellation error
@@ -73,6 +96,9 @@ export const useHomeData = () => {
         setProfile((prev) =>
           prev ? { ...prev, coins: e.record.coins } : null,
         );
+        setWallet((prev) =>
+          prev ? { ...prev, coins: e.record.coins } : null,
+        );
       }
     });
 
@@ -81,5 +107,15 @@ export const useHomeData = () => {
     };
   }, [pb.authStore.model?.id]);
 
-  return { loading, profile, banners, games, refetch: fetchData };
+  return {
+    loading,
+    profile,
+    wallet,
+    banners,
+    featuredGames,
+    games,
+    checkIn,
+    spinsLeft,
+    refetch: fetchData,
+  };
 };
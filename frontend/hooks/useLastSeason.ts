@@ -0,0 +1,33 @@
+import { useState, useEffect, useCallback } from "react";
+import { pb } from "@/utils/pocketbase";
+import { LeaderboardPeriod, LeaderboardSeason } from "@/types";
+
+/**
+ * The most recent ended season of a leaderboard period (with the user's own
+ * result and reward), null for all-time or while none ended yet.
+ */
+export const useLastSeason = (period: LeaderboardPeriod) => {
+  const [season, setSeason] = useState<LeaderboardSeason | null>(null);
+
+  const fetchSeason = useCallback(async () => {
+    if (period === "all_time") {
+      setSeason(null);
+      return;
+    }
+
+    try {
+      const data = await pb.send("/api/leaderboard/seasons", {
+        query: { period, perPage: 1 },
+      });
+      setSeason(data.items[0] ?? null);
+    } catch (err) {
+      console.error("Error fetching last season:", err);
+    }
+  }, [period]);
+
+  useEffect(() => {
+    fetchSeason();
+  }, [fetchSeason]);
+
+  return { season, refetch: fetchSeason };
+};
@@ -1,50 +1,85 @@
 import { useState, useEffect, useCallback } from "react";
 import { pb } from "@/utils/pocketbase";
 import { getStorageUrl } from "@/utils/imageHelpers";
+import { LeaderboardPeriod } from "@/types";
 
 export type LeaderboardItem = {
   id: string;
   username: string;
+  // empty when the user hides their display name
+  name: string;
   avatar: string;
   score: number;
   rank: number;
+  level: number;
+  dailyStreak: number;
+  totalGamesPlayed: number;
+  isOnline: boolean;
 };
 
-export const useLeaderboard = () => {
+// "around_me" returns the players just above and below the current user
+export type LeaderboardMode = "top" | "around_me";
+// "friends" ranks the current user among their friends only, "country"
+// among the players of their country
+export type LeaderboardScope = "global" | "friends" | "country";
+
+export const useLeaderboard = (
+  period: LeaderboardPeriod = "all_time",
+  mode: LeaderboardMode = "top",
+  scope: LeaderboardScope = "global"
+) => {
   const [loading, setLoading] = useState(true);
   const [leaderboard, setLeaderboard] = useState<LeaderboardItem[]>([]);
   const [currentUserRank, setCurrentUserRank] = useState<number>(0);
+  const [currentUserScore, setCurrentUserScore] = useState<number>(0);
+  // when the period's standings start over (null for all time)
+  const [resetsAt, setResetsAt] = useState<Date | null>(null);
 
   const fetchLeaderboard = useCallback(async () => {
     try {
       setLoading(true);
 
-      // Call the custom Go route
+      // Call the custom Go route (ranks by the coins earned in the period)
       const data = await pb.send("/api/leaderboard", {
         method: "GET",
+        query: { period, mode, scope },
       });
 
       // Map the results
       const formatted = data.leaderboard.map((item: any) => ({
         id: item.id,
         username: item.username,
+        name: item.name,
         avatar: getStorageUrl(item, item.avatar), // Make sure helper is updated
         score: item.coins,
         rank: item.rank,
+        level: item.level,
+        dailyStreak: item.daily_streak,
+        totalGamesPlayed: item.total_games_played,
+        isOnline: item.is_online,
       }));
 
       setLeaderboard(formatted);
       setCurrentUserRank(data.user_rank);
+      setCurrentUserScore(data.user_score);
+      setResetsAt(data.resets_at ? new Date(data.resets_at) : null);
     } catch (err) {
       console.error("Error fetching leaderboard:", err);
     } finally {
       setLoading(false);
     }
-  }, []);
+  }, [period, mode, scope]);
 
   useEffect(() => {
     fetchLeaderboard();
   }, [fetchLeaderboard]);
 
-  return { leaderboard, currentUserRank, loading, refetch: fetchLeaderboard };
+  return {
+    leaderboard,
+    currentUserRank,
+    currentUserScore,
+    resetsAt,
+    loading,
+    refetch: fetchLeaderboard,
+  };
 };
@@ -2,6 +2,7 @@ import { useState } from "react";
 import { pb } from "@/utils/pocketbase";
 import { Alert } from "react-native";
 import { useUserStats } from "@/context/UserStatsContext";
+import { apiMessage } from "@/utils/apiMessages";
 
 export const useLuckySpin = () => {
   const [loading, setLoading] = useState(false);
@@ -26,7 +27,7 @@ export const useLuckySpin = () => {
       if (!data.success) {
         Alert.alert(
           "Out of Spins",
-          data.message || "Please wait for tomorrow.",
+          apiMessage(data, "Please wait for tomorrow."),
         );
         return null;
       }
@@ -39,10 +40,7 @@ export const useLuckySpin = () => {
         rewardAmount: data.reward,
       };
     } catch (error: any) {
-      // PocketBase errors usually have a 'data' object or 'message'
-      const errorMsg =
-        error.data?.message || error.message || "Something went wrong.";
-      Alert.alert("Error", errorMsg);
+      Alert.alert("Error", apiMessage(error, "Something went wrong."));
       console.error("Spin error:", error);
       return null;
     } finally {
@@ -0,0 +1,38 @@
+import { useState, useEffect, useCallback } from "react";
+import { pb } from "@/utils/pocketbase";
+import { PublicProfile } from "@/types";
+import { getStorageUrl } from "@/utils/imageHelpers";
+
+/**
+ * Another player's public profile (the sections they hid are null).
+ */
+export const usePublicProfile = (userId: string | null) => {
+  const [profile, setProfile] = useState<PublicProfile | null>(null);
+  const [loading, setLoading] = useState(false);
+
+  const fetchProfile = useCallback(async () => {
+    if (!userId) {
+      setProfile(null);
+      return;
+    }
+
+    try {
+      setLoading(true);
+      const data = await pb.send(`/api/users/${userId}/profile`, {
+        method: "GET",
+      });
+      setProfile({ ...data, avatar_url: getStorageUrl(data, data.avatar_url) });
+    } catch (err) {
+      console.error("Error fetching public profile:", err);
+      setProfile(null);
+    } finally {
+      setLoading(false);
+    }
+  }, [userId]);
+
+  useEffect(() => {
+    fetchProfile();
+  }, [fetchProfile]);
+
+  return { profile, loading, refetch: fetchProfile };
+};
@@ -0,0 +1,47 @@
+import { useState, useEffect, useCallback } from "react";
+import { pb } from "@/utils/pocketbase";
+import { Game } from "@/types";
+import { getGameImageUrl } from "@/utils/imageHelpers";
+
+/**
+ * Games recommended for the current user from their play history
+ * (popular games for new players).
+ */
+export const useRecommendedGames = (limit = 10) => {
+  const [games, setGames] = useState<Game[]>([]);
+  const [loading, setLoading] = useState(true);
+
+  const fetchRecommended = useCallback(async () => {
+    if (!pb.authStore.isValid) {
+      setLoading(false);
+      return;
+    }
+
+    try {
+      setLoading(true);
+      const data = await pb.send("/api/me/recommended-games", {
+        query: { limit },
+      });
+
+      setGames(
+        data.items.map((game: any) => ({
+          ...game,
+          image: getGameImageUrl(game),
+          category: game.expand?.category?.slug,
+        })),
+      );
+    } catch (error: any) {
+      if (!error.isAbort) {
+        console.error("Error fetching recommended games:", error);
+      }
+    } finally {
+      setLoading(false);
+    }
+  }, [limit]);
+
+  useEffect(() => {
+    fetchRecommended();
+  }, [fetchRecommended]);
+
+  return { games, loading, refetch: fetchRecommended };
+};
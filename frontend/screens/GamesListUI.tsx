@@ -23,28 +23,18 @@ import Animated, {
 
 // --- Local Imports ---
 import { useTheme } from "@/context/ThemeContext";
-import { Theme, Game } from "@/types";
+import { Theme, Game, GameSort } from "@/types";
 import { GameCategories } from "@/components/games/GameCategories";
 import { SearchBar } from "@/components/common/SearchBar";
 import { useGameCatalog } from "@/hooks/useGameCatalog";
+import { useCategories } from "@/hooks/useCategories";
 
 // --- CONSTANTS ---
 const TAB_BAR_OFFSET = 120; // Space for the bottom tab bar
 const GAP = 15;
 const PADDING = 20;
 const MAX_WIDTH = 1024;
-
-const CATEGORY_LIST = [
-  "All",
-  "Puzzle",
-  "Action",
-  "Strategy",
-  "Racing",
-  "Sports",
-  "Adventure",
-  "Casual",
-  "Arcade",
-];
+const SORT_OPTIONS: GameSort[] = ["new", "trending", "popular", "rating"];
 
 // --- 1. POSTER CARD (Vertical) ---
 const LibraryGameCard = ({
@@ -76,6 +66,7 @@ const LibraryGameCard = ({
       url: item.url,
       orientation: item.orientation,
       description: item.description || "",
+      rating: item.rating_count ? item.rating_avg?.toFixed(1) : "",
     },
   };
 
@@ -102,7 +93,9 @@ const LibraryGameCard = ({
             />
             <View style={styles.ratingBadge}>
               <Ionicons name="star" size={9} color="#FFD700" />
-              <Text style={styles.ratingText}>4.5</Text>
+              <Text style={styles.ratingText}>
+                {item.rating_count ? item.rating_avg?.toFixed(1) : "-"}
+              </Text>
             </View>
           </View>
 
@@ -158,11 +151,35 @@ export const GamesListUI: React.FC = () => {
     setSearchQuery,
     selectedCategory,
     setSelectedCategory,
+    favoritesOnly,
+    setFavoritesOnly,
+    setSortBy,
     refresh,
     loadingMore,
     loadMore,
   } = useGameCatalog();
 
+  // Categories are managed on the server (game_categories collection)
+  const { categories } = useCategories();
+  const categoryList = useMemo(
+    () => ["All", ...categories.map((c) => c.slug)],
+    [categories],
+  );
+  const categoryLabels = useMemo(
+    () => Object.fromEntries(categories.map((c) => [c.slug, c.name])),
+    [categories],
+  );
+
+  const sortLabels = useMemo(
+    () => ({
+      new: t("gamesList.sort.new", "New"),
+      trending: t("gamesList.sort.trending", "Trending"),
+      popular: t("gamesList.sort.popular", "Popular"),
+      rating: t("gamesList.sort.rating", "Top Rated"),
+    }),
+    [t],
+  );
+
   const renderFooter = () => {
     if (!loadingMore) return null;
     return (
@@ -179,9 +196,21 @@ export const GamesListUI: React.FC = () => {
       <SafeAreaView style={styles.container} edges={["top"]}>
         {/* HEADER SECTION */}
         <View style={styles.headerContainer}>
-          <Text style={styles.pageTitle}>
-            {t("gamesList.title", "Game Library")}
-          </Text>
+          <View style={styles.titleRow}>
+            <Text style={styles.pageTitle}>
+              {t("gamesList.title", "Game Library")}
+            </Text>
+            <TouchableOpacity
+              onPress={() => setFavoritesOnly(!favoritesOnly)}
+              accessibilityLabel={t("gamesList.favoritesOnly", "Favorites")}
+            >
+              <Ionicons
+                name={favoritesOnly ? "heart" : "heart-outline"}
+                size={26}
+                color={favoritesOnly ? theme.primary : theme.textSecondary}
+              />
+            </TouchableOpacity>
+          </View>
 
           <SearchBar
             placeholder={t(
@@ -193,10 +222,20 @@ export const GamesListUI: React.FC = () => {
           />
 
           <GameCategories
-            categories={CATEGORY_LIST}
+            categories={categoryList}
+            labels={categoryLabels}
             selectedCategory={selectedCategory}
             onSelectCategory={setSelectedCategory}
           />
+
+          {/* Sorting only applies when browsing, searches are ranked by relevance */}
+          {searchQuery.trim().length === 0 && (
+            <GameCategories
+              categories={SORT_OPTIONS}
+              labels={sortLabels}
+              onSelectCategory={(sort) => setSortBy(sort as GameSort)}
+            />
+          )}
         </View>
 
         {/* GAMES GRID */}
@@ -275,6 +314,11 @@ const createStyles = (theme: Theme) =>
       paddingTop: 10,
       paddingBottom: 5,
     },
+    titleRow: {
+      flexDirection: "row",
+      justifyContent: "space-between",
+      alignItems: "flex-start",
+    },
     pageTitle: {
       fontSize: 28,
       fontWeight: "800",
@@ -16,6 +16,7 @@ import { useTheme } from "@/context/ThemeContext";
 import Colors from "@/constants/Colors";
 import { Theme } from "@/types";
 import { useHomeData } from "@/hooks/useHomeData";
+import { useRecommendedGames } from "@/hooks/useRecommendedGames";
 
 // --- Component Imports ---
 import { AppTitleHeader } from "@/components/layout/AppTitleHeader";
@@ -38,7 +39,10 @@ const HomePageUI: React.FC = () => {
   // Pass theme to styles to access colors
   const styles = useMemo(() => createStyles(theme), [theme]);
 
-  const { loading, profile, banners, games, refetch } = useHomeData();
+  const { loading, profile, banners, featuredGames, games, refetch } =
+    useHomeData();
+  const { games: recommendedGames, refetch: refetchRecommended } =
+    useRecommendedGames();
 
   if (loading && !profile) {
     // Uses rootBackground to ensure full screen color while loading
@@ -66,7 +70,10 @@ const HomePageUI: React.FC = () => {
           refreshControl={
             <RefreshControl
               refreshing={loading}
-              onRefresh={refetch}
+              onRefresh={() => {
+                refetch();
+                refetchRecommended();
+              }}
               tintColor={theme.primary}
             />
           }
@@ -83,11 +90,16 @@ const HomePageUI: React.FC = () => {
 
           <ContinuePlaying data={games} />
 
+          <ContinuePlaying
+            data={recommendedGames}
+            title={t("home.recommended", "Recommended for You")}
+          />
+
           <SmartBanner />
 
           <ReferralCTA />
 
-          <FeaturedGames />
+          <FeaturedGames data={featuredGames} />
         </ScrollView>
       </SafeAreaView>
     </View>
@@ -19,25 +19,65 @@ import { MaterialCommunityIcons } from "@expo/vector-icons";
 import { Stack } from "expo-router";
 
 import { useTheme } from "@/context/ThemeContext";
-import { useLeaderboard } from "@/hooks/useLeaderboard";
+import { useLeaderboard, LeaderboardScope } from "@/hooks/useLeaderboard";
+import { useLastSeason } from "@/hooks/useLastSeason";
 import { useAuth } from "@/context/AuthContext";
 import { getStorageUrl } from "@/utils/imageHelpers";
+import { LeaderboardPeriod } from "@/types";
 
 // --- CONSTANTS ---
 const MAX_WIDTH = 1024;
 const TAB_BAR_OFFSET = 120;
 
+const PERIOD_TABS: { key: LeaderboardPeriod; label: string }[] = [
+  { key: "daily", label: "Daily" },
+  { key: "weekly", label: "Weekly" },
+  { key: "monthly", label: "Monthly" },
+  { key: "all_time", label: "All Time" },
+];
+
+const SCOPE_TABS: { key: LeaderboardScope; label: string }[] = [
+  { key: "global", label: "Global" },
+  { key: "friends", label: "Friends" },
+  { key: "country", label: "Country" },
+];
+
+// "2d 5h", "5h 12m" or "12m" until the standings start over
+const formatTimeLeft = (until: Date) => {
+  const minutes = Math.max(
+    0,
+    Math.floor((until.getTime() - Date.now()) / 60000),
+  );
+  const days = Math.floor(minutes / 1440);
+  const hours = Math.floor((minutes % 1440) / 60);
+  if (days > 0) return `${days}d ${hours}h`;
+  if (hours > 0) return `${hours}h ${minutes % 60}m`;
+  return `${minutes}m`;
+};
+
 // --- SUB-COMPONENTS ---
-const FilterTabs = ({ activeTab, onTabChange, theme }: any) => (
+const FilterTabs = ({
+  activeTab,
+  onTabChange,
+  theme,
+  t,
+  tabs = PERIOD_TABS,
+  i18nPrefix = "leaderboard.periods",
+  style,
+}: any) => (
   <View
-    style={[styles.filterContainer, { backgroundColor: "rgba(0,0,0,0.2)" }]}
+    style={[
+      styles.filterContainer,
+      { backgroundColor: "rgba(0,0,0,0.2)" },
+      style,
+    ]}
   >
-    {["All Time", "Weekly"].map((tab) => {
-      const isActive = activeTab === tab;
+    {tabs.map(({ key, label }: { key: string; label: string }) => {
+      const isActive = activeTab === key;
       return (
         <TouchableOpacity
-          key={tab}
-          onPress={() => onTabChange(tab)}
+          key={key}
+          onPress={() => onTabChange(key)}
           style={[
             styles.filterTab,
             isActive && { backgroundColor: theme.buttonSecondary },
@@ -53,7 +93,7 @@ const FilterTabs = ({ activeTab, onTabChange, theme }: any) => (
               },
             ]}
           >
-            {tab}
+            {t(`${i18nPrefix}.${key}`, label)}
           </Text>
         </TouchableOpacity>
       );
@@ -124,11 +164,20 @@ const LeaderboardRow = ({ item, isCurrentUser, theme }: any) => {
       >
         {item.rank}
       </Text>
-      <Image source={{ uri: item.avatar }} style={styles.rowAvatar} />
+      <View>
+        <Image source={{ uri: item.avatar }} style={styles.rowAvatar} />
+        {item.isOnline && <View style={styles.onlineDot} />}
+      </View>
       <View style={styles.rowInfo}>
         <Text style={[styles.rowName, { color: theme.textPrimary }]}>
-          {item.username} {isCurrentUser ? "(You)" : ""}
+          {item.name || item.username} {isCurrentUser ? "(You)" : ""}
         </Text>
+        {item.level > 0 && (
+          <Text style={[styles.rowDetails, { color: theme.textSecondary }]}>
+            Lv {item.level}
+            {item.dailyStreak > 0 ? ` · 🔥 ${item.dailyStreak}` : ""}
+          </Text>
+        )}
       </View>
       <Text style={[styles.rowScore, { color: theme.textPrimary }]}>
         {item.score?.toLocaleString()}
@@ -140,14 +189,23 @@ const LeaderboardRow = ({ item, isCurrentUser, theme }: any) => {
 export const LeaderboardUI: React.FC = () => {
   const { t } = useTranslation();
   const theme = useTheme();
-  const [activeTab, setActiveTab] = useState("All Time");
+  const [activeTab, setActiveTab] = useState<LeaderboardPeriod>("weekly");
+  const [scope, setScope] = useState<LeaderboardScope>("global");
   const { session } = useAuth(); // Local user details
 
   const { width: windowWidth } = useWindowDimensions();
   const isDesktop = windowWidth > 768;
 
   // DATA HOOK: Custom Go Route Integration
-  const { leaderboard, currentUserRank, loading, refetch } = useLeaderboard();
+  const {
+    leaderboard,
+    currentUserRank,
+    currentUserScore,
+    resetsAt,
+    loading,
+    refetch,
+  } = useLeaderboard(activeTab, "top", scope);
+  const { season: lastSeason } = useLastSeason(activeTab);
 
   const flatListRef = useRef<FlatList>(null);
 
@@ -170,14 +228,14 @@ export const LeaderboardUI: React.FC = () => {
         id: session.id,
         username: session.username || session.name || "Player",
         // Using session object for image resolution
-        avatar: getStorageUrl(session, session.avatar),
-        score: session.coins || 0,
+        avatar: getStorageUrl(session, session.avatar_url),
+        score: currentUserScore,
         rank: currentUserRank,
         type: "user",
       });
     }
     return data;
-  }, [leaderboard, currentUserRank, session]);
+  }, [leaderboard, currentUserRank, currentUserScore, session]);
 
   // Handle auto-scroll to current user
   useEffect(() => {
@@ -257,7 +315,33 @@ export const LeaderboardUI: React.FC = () => {
               activeTab={activeTab}
               onTabChange={setActiveTab}
               theme={theme}
+              t={t}
+            />
+            <FilterTabs
+              activeTab={scope}
+              onTabChange={setScope}
+              theme={theme}
+              t={t}
+              tabs={SCOPE_TABS}
+              i18nPrefix="leaderboard.scopes"
+              style={styles.scopeTabs}
             />
+            {resetsAt && (
+              <Text style={styles.resetText}>
+                {t("leaderboard.resetsIn", "Resets in {{time}}", {
+                  time: formatTimeLeft(resetsAt),
+                })}
+              </Text>
+            )}
+            {lastSeason?.user_result && (
+              <Text style={styles.resetText}>
+                {t(
+                  "leaderboard.lastSeasonResult",
+                  "Last season: #{{rank}} · +{{reward}} coins",
+                  lastSeason.user_result,
+                )}
+              </Text>
+            )}
           </View>
 
           <View style={styles.podiumContainer}>
@@ -379,6 +463,10 @@ const styles = StyleSheet.create({
     justifyContent: "space-between",
     maxWidth: 400, // Limit width on desktop
   },
+  scopeTabs: {
+    width: "55%",
+    marginTop: 8,
+  },
   filterTab: {
     flex: 1,
     paddingVertical: 8,
@@ -390,6 +478,12 @@ const styles = StyleSheet.create({
     fontSize: 14,
     fontWeight: "700",
   },
+  resetText: {
+    marginTop: 8,
+    fontSize: 12,
+    fontWeight: "600",
+    color: "rgba(255,255,255,0.7)",
+  },
   podiumContainer: {
     flexDirection: "row",
     justifyContent: "center",
@@ -515,6 +609,21 @@ const styles = StyleSheet.create({
     fontSize: 16,
     fontWeight: "600",
   },
+  rowDetails: {
+    fontSize: 12,
+    marginTop: 2,
+  },
+  onlineDot: {
+    position: "absolute",
+    right: 12,
+    bottom: 0,
+    width: 12,
+    height: 12,
+    borderRadius: 6,
+    backgroundColor: "#22c55e",
+    borderWidth: 2,
+    borderColor: "#0f172a",
+  },
   rowScore: {
     fontSize: 16,
     fontWeight: "bold",
@@ -67,12 +67,13 @@ export const ProfileUI: React.FC = () => {
       const userId = pb.authStore.model?.id;
       if (!userId) return;
 
-      const [profileData, leaderboardData] = await Promise.all([
+      const [profileData, leaderboardData, me] = await Promise.all([
         pb.collection("users").getOne(userId, { requestKey: "profile_fetch" }),
         pb.send("/api/leaderboard", {
           method: "GET",
           requestKey: "rank_fetch",
         }),
+        pb.send("/api/me", { method: "GET", requestKey: "me_fetch" }),
       ]);
 
       setProfile({
@@ -82,8 +83,16 @@ export const ProfileUI: React.FC = () => {
         avatar: getStorageUrl(profileData, profileData.avatar_url),
         coins: profileData.coins,
         joinDate: profileData.created,
-        level: profileData.level,
+        level: me.level,
+        xp: me.xp,
+        nextLevelXp: me.next_level_xp,
         referralCode: profileData.referral_code,
+        hideName: profileData.hide_name,
+        hideLevel: profileData.hide_level,
+        hideAchievements: profileData.hide_achievements,
+        hideJoinDate: profileData.hide_join_date,
+        shareFavorites: profileData.share_favorites,
+        country: profileData.country,
       } as unknown as UserProfile);
 
       if (leaderboardData.user_rank) {
@@ -111,8 +120,9 @@ export const ProfileUI: React.FC = () => {
               ? {
                   ...prev,
                   coins: updated.coins,
+                  level: updated.level,
                   name: updated.name || updated.username,
-                  avatar: getStorageUrl(updated, updated.avatar),
+                  avatar: getStorageUrl(updated, updated.avatar_url),
                 }
               : null,
           );
@@ -201,7 +211,7 @@ export const ProfileUI: React.FC = () => {
     }
   };
 
-  const playerLevel = profile ? Math.floor(profile.coins / 1000) + 1 : 1;
+  const playerLevel = profile?.level || 1;
 
   if (loading && !profile) {
     return (
@@ -279,6 +289,13 @@ export const ProfileUI: React.FC = () => {
                 <Text style={styles.userTitle}>
                   {t("profile.playerTitle", "Cyber Runner")}
                 </Text>
+                {profile && (
+                  <Text style={styles.userTitle}>
+                    {profile.nextLevelXp
+                      ? `XP ${profile.xp.toLocaleString()} / ${profile.nextLevelXp.toLocaleString()}`
+                      : `XP ${profile.xp.toLocaleString()}`}
+                  </Text>
+                )}
               </View>
 
               <View style={styles.statsGrid}>
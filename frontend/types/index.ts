@@ -9,33 +9,82 @@ export type ValidRoutes =
   | "/airdrop"
   | "/leaderboard"; // Use specific routes for type safety
 
-export type GameCategories =
-  | "Puzzle"
-  | "Action"
-  | "Strategy"
-  | "Racing"
-  | "Sports"
-  | "Adventure"
-  | "Casual"
-  | "Arcade"; // Add category
+// Category slug from the `game_categories` collection (e.g. "puzzle", "match-3")
+export type GameCategories = string;
+
+export interface GameCategory {
+  id: string;
+  slug: GameCategories;
+  name: string;
+  names: Record<string, string>;
+  icon: string;
+  sort_order: number;
+  game_count: number;
+}
+
+export type LeaderboardPeriod = "daily" | "weekly" | "monthly" | "all_time";
 
 export interface LeaderboardUser {
   id: string;
   username: string;
+  name?: string;
   avatar: string;
   coins: number;
   rank: number;
+  level?: number;
   dailyStreak: number;
   totalGamesPlayed: number;
   isOnline?: boolean;
 }
 
+// a player as shown in the friends lists and search
+export interface FriendUser {
+  id: string;
+  username: string;
+  name: string;
+  avatar_url: string;
+  level: number;
+  friendship?: "none" | "friends" | "pending_outgoing" | "pending_incoming";
+}
+
+// what /api/users/{id}/profile shows of another player, null when hidden
+export interface PublicProfile {
+  id: string;
+  username: string;
+  name: string | null;
+  avatar_url: string;
+  level: number | null;
+  joined: string | null;
+  achievements:
+    | {
+        id: string;
+        title: string;
+        description: string;
+        icon: string;
+        completed_at: string;
+      }[]
+    | null;
+  favorite_games: Game[] | null;
+  friendship?: FriendUser["friendship"];
+}
+
+export interface Friendship {
+  id: string;
+  user: FriendUser;
+  source: "request" | "referral";
+  created: string;
+  accepted_at?: string;
+}
+
 export type DailyReward = {
   day: number;
   reward: number;
   claimed: boolean;
 };
 
+// catalog orders supported by /api/games
+export type GameSort = "trending" | "popular" | "new" | "rating";
+
 export type Game = {
   id: string;
   title: string;
@@ -44,6 +93,8 @@ export type Game = {
   orientation: "landscape" | "portrait";
   description?: string;
   category?: GameCategories;
+  rating_avg?: number;
+  rating_count?: number;
 };
 
 export type Theme = {
@@ -101,6 +152,20 @@ export interface ReferralStats {
   pendingRewards: number;
 }
 
+// /api/home sections
+export interface HomeWallet {
+  coins: number;
+  play_coins_today: number;
+  play_coins_daily_cap: number;
+}
+
+export interface HomeCheckIn {
+  claimed_today: boolean;
+  streak: number;
+  next_day: number;
+  next_reward: number;
+}
+
 export interface UserProfile {
   id: string;
   username: string;
@@ -109,8 +174,20 @@ export interface UserProfile {
   avatar: string;
   joinDate: string;
   level: number;
+  xp: number;
+  // total XP of the next level, null at the top of the level curve
+  nextLevelXp: number | null;
   coins: number;
   referralCode: string;
+  // keeps the display name off the public leaderboards
+  hideName: boolean;
+  // public profile sections (favorite games are private unless shared)
+  hideLevel: boolean;
+  hideAchievements: boolean;
+  hideJoinDate: boolean;
+  shareFavorites: boolean;
+  // ISO 3166-1 alpha-2 code, for the country leaderboard
+  country: string;
 }
 
 export interface ProfileProps {
@@ -136,3 +213,23 @@ export type LeaderboardEntry = {
   coins: number;
   isCurrentUser?: boolean; // Optional flag for the current user
 };
+
+// Final result of a player in an ended leaderboard season
+export type SeasonStanding = {
+  rank: number;
+  user: string;
+  username: string;
+  avatar: string;
+  score: number;
+  reward: number;
+};
+
+export type LeaderboardSeason = {
+  id: string;
+  period: Exclude<LeaderboardPeriod, "all_time">;
+  starts_at: string;
+  ends_at: string;
+  players: number;
+  winners: SeasonStanding[];
+  user_result: SeasonStanding | null;
+};
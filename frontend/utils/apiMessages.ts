@@ -0,0 +1,16 @@
+import i18n from "@/i18n";
+
+/**
+ * Text to show for a failed request or a `success: false` response.
+ * The server sends a stable `code` (data.code of the errors) next to a
+ * message already in the request language, an `errors.<code>` translation
+ * in the app takes precedence over it.
+ */
+export const apiMessage = (source: any, fallback: string): string => {
+  const code: string | undefined =
+    source?.code ?? source?.response?.data?.code;
+  const message: string = source?.message || fallback;
+
+  if (!code) return message;
+  return i18n.t(`errors.${code}`, { defaultValue: message });
+};
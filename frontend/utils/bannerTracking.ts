@@ -0,0 +1,44 @@
+import { pb } from "@/utils/pocketbase";
+
+type BannerEventType = "impression" | "click";
+
+// beacons are sent in batches, the server deduplicates them per user and day
+const FLUSH_DELAY_MS = 5000;
+
+let queue: { banner_id: string; type: BannerEventType }[] = [];
+let flushTimer: ReturnType<typeof setTimeout> | null = null;
+
+// already queued in this app session
+const seen = new Set<string>();
+
+const flush = async () => {
+  flushTimer = null;
+  if (queue.length === 0) return;
+
+  const events = queue;
+  queue = [];
+
+  try {
+    await pb.send("/api/banners/events", {
+      method: "POST",
+      body: { events },
+    });
+  } catch (error) {
+    // analytics only, never bother the user
+    console.warn("Could not send banner events:", error);
+  }
+};
+
+export const trackBannerEvent = (bannerId: string, type: BannerEventType) => {
+  const key = `${bannerId}:${type}`;
+  if (!bannerId || seen.has(key)) return;
+  seen.add(key);
+
+  queue.push({ banner_id: bannerId, type });
+  if (type === "click") {
+    // clicks usually navigate away, don't wait
+    flush();
+  } else if (!flushTimer) {
+    flushTimer = setTimeout(flush, FLUSH_DELAY_MS);
+  }
+};
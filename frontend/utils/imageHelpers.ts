@@ -9,8 +9,22 @@ export const getStorageUrl = (
   // If it's already a full URL, return it
   if (filename.startsWith("http")) return filename;
 
+  // Served by the backend (e.g. the generated avatars), relative to its URL
+  if (filename.startsWith("/")) return pb.buildURL(filename);
+
   // PocketBase SDK helper: (record, filename, options)
   return pb.files.getURL(record, filename, {
     thumb: "100x100", // Optional: PocketBase can auto-generate thumbs
   });
 };
+
+/**
+ * Game art through the backend's image endpoint: the mirrored copy of
+ * `games.image` resized to (about) `width` pixels, or a redirect to the
+ * original while it isn't mirrored yet.
+ */
+export const getGameImageUrl = (game: any, width = 480) => {
+  if (!game?.id) return getStorageUrl(game, game?.image);
+
+  return pb.buildURL(`/api/games/${game.id}/image?w=${width}`);
+};
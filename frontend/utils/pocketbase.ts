@@ -2,6 +2,7 @@ import PocketBase, { BaseAuthStore } from "pocketbase";
 import AsyncStorage from "@react-native-async-storage/async-storage";
 import { Platform } from "react-native";
 import EventSource from "react-native-sse";
+import i18n from "@/i18n";
 
 // Polyfill EventSource
 // @ts-ignore
@@ -15,6 +16,17 @@ export const pb = new PocketBase(pbUrl);
 // Disable auto-cancellation (prevents the "autocancelled" errors)
 pb.autoCancellation(false);
 
+// The server targets the featured games and banners per platform
+// and localizes the games, banners and achievements per language
+pb.beforeSend = (url, options) => {
+  options.headers = {
+    ...options.headers,
+    "X-Client-Platform": Platform.OS,
+    "Accept-Language": i18n.language,
+  };
+  return { url, options };
+};
+
 export const persistAuth = async () => {
   // Manual save: takes current state and puts it in storage
   const authData = JSON.stringify({